@@ -0,0 +1,79 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command sentry-sink is a local Sentry compatible ingest server for development. Point
+// SENTRY_DSN at it and browse the received events at http://localhost:9000/.
+//
+//	go run github.com/bborbe/sentry/cmd/sentry-sink -listen localhost:9000 -jsonl /tmp/sentry.jsonl
+//	SENTRY_DSN=http://public@localhost:9000/1 go run ./example
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/http"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var (
+	listenPtr    = flag.String("listen", "localhost:9000", "address to listen on")
+	jsonlPtr     = flag.String("jsonl", "", "file received events are appended to as JSON lines")
+	maxEventsPtr = flag.Int("max-events", 1000, "number of events kept in memory")
+)
+
+func main() {
+	defer glog.Flush()
+	glog.CopyStandardLogTo("info")
+	runtime.GOMAXPROCS(runtime.NumCPU())
+	_ = flag.Set("logtostderr", "true")
+	_ = flag.Set("v", "2")
+
+	time.Local = time.UTC
+	glog.V(2).Infof("set global timezone to UTC")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	flag.Parse()
+
+	sink, err := sentrytest.NewSink(ctx, func(options *sentrytest.SinkOptions) {
+		options.JSONLPath = *jsonlPtr
+		options.MaxEvents = *maxEventsPtr
+	})
+	if err != nil {
+		glog.Exitf("create sink failed: %v", err)
+	}
+	defer sink.Close()
+
+	listener, err := net.Listen("tcp", *listenPtr)
+	if err != nil {
+		glog.Exitf("listen on %s failed: %v", *listenPtr, err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	glog.V(0).Infof("sentry-sink listening on %s", listener.Addr())
+	glog.V(0).Infof("use SENTRY_DSN=http://public@localhost:%d/1", port)
+	glog.V(0).Infof("browse events at http://localhost:%d/", port)
+
+	server := &http.Server{
+		Handler:           sink,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		glog.Exitf("serve failed: %v", err)
+	}
+}
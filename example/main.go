@@ -18,8 +18,6 @@ import (
 	libsentry "github.com/bborbe/sentry"
 )
 
-var dsnPtr = flag.String("dsn", "", "sentry dsn")
-
 func main() {
 	defer glog.Flush()
 	glog.CopyStandardLogTo("info")
@@ -34,10 +32,8 @@ func main() {
 
 	flag.Parse()
 
-	client, err := libsentry.NewClient(ctx, sentry.ClientOptions{
-		Dsn:  *dsnPtr,
-		Tags: map[string]string{"options-data": "options-value"},
-	})
+	// configured by SENTRY_DSN, SENTRY_TAGS, ...; without dsn events are only logged
+	client, err := libsentry.NewClientFromEnv(ctx)
 	if err != nil {
 		glog.Exitf("create client failed: %v", err)
 	}
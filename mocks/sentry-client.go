@@ -2,6 +2,7 @@
 package mocks
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -10,6 +11,25 @@ import (
 )
 
 type SentryClient struct {
+	AddBreadcrumbStub        func(context.Context, *sentrya.Breadcrumb)
+	addBreadcrumbMutex       sync.RWMutex
+	addBreadcrumbArgsForCall []struct {
+		arg1 context.Context
+		arg2 *sentrya.Breadcrumb
+	}
+	CaptureCheckInStub        func(*sentrya.CheckIn, *sentrya.MonitorConfig, sentrya.EventModifier) *sentrya.EventID
+	captureCheckInMutex       sync.RWMutex
+	captureCheckInArgsForCall []struct {
+		arg1 *sentrya.CheckIn
+		arg2 *sentrya.MonitorConfig
+		arg3 sentrya.EventModifier
+	}
+	captureCheckInReturns struct {
+		result1 *sentrya.EventID
+	}
+	captureCheckInReturnsOnCall map[int]struct {
+		result1 *sentrya.EventID
+	}
 	CaptureExceptionStub        func(error, *sentrya.EventHint, sentrya.EventModifier) *sentrya.EventID
 	captureExceptionMutex       sync.RWMutex
 	captureExceptionArgsForCall []struct {
@@ -57,10 +77,119 @@ type SentryClient struct {
 	flushReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	StartTransactionStub        func(context.Context, string, ...sentrya.SpanOption) *sentrya.Span
+	startTransactionMutex       sync.RWMutex
+	startTransactionArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []sentrya.SpanOption
+	}
+	startTransactionReturns struct {
+		result1 *sentrya.Span
+	}
+	startTransactionReturnsOnCall map[int]struct {
+		result1 *sentrya.Span
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *SentryClient) AddBreadcrumb(arg1 context.Context, arg2 *sentrya.Breadcrumb) {
+	fake.addBreadcrumbMutex.Lock()
+	fake.addBreadcrumbArgsForCall = append(fake.addBreadcrumbArgsForCall, struct {
+		arg1 context.Context
+		arg2 *sentrya.Breadcrumb
+	}{arg1, arg2})
+	stub := fake.AddBreadcrumbStub
+	fake.recordInvocation("AddBreadcrumb", []interface{}{arg1, arg2})
+	fake.addBreadcrumbMutex.Unlock()
+	if stub != nil {
+		fake.AddBreadcrumbStub(arg1, arg2)
+	}
+}
+
+func (fake *SentryClient) AddBreadcrumbCallCount() int {
+	fake.addBreadcrumbMutex.RLock()
+	defer fake.addBreadcrumbMutex.RUnlock()
+	return len(fake.addBreadcrumbArgsForCall)
+}
+
+func (fake *SentryClient) AddBreadcrumbCalls(stub func(context.Context, *sentrya.Breadcrumb)) {
+	fake.addBreadcrumbMutex.Lock()
+	defer fake.addBreadcrumbMutex.Unlock()
+	fake.AddBreadcrumbStub = stub
+}
+
+func (fake *SentryClient) AddBreadcrumbArgsForCall(i int) (context.Context, *sentrya.Breadcrumb) {
+	fake.addBreadcrumbMutex.RLock()
+	defer fake.addBreadcrumbMutex.RUnlock()
+	argsForCall := fake.addBreadcrumbArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *SentryClient) CaptureCheckIn(arg1 *sentrya.CheckIn, arg2 *sentrya.MonitorConfig, arg3 sentrya.EventModifier) *sentrya.EventID {
+	fake.captureCheckInMutex.Lock()
+	ret, specificReturn := fake.captureCheckInReturnsOnCall[len(fake.captureCheckInArgsForCall)]
+	fake.captureCheckInArgsForCall = append(fake.captureCheckInArgsForCall, struct {
+		arg1 *sentrya.CheckIn
+		arg2 *sentrya.MonitorConfig
+		arg3 sentrya.EventModifier
+	}{arg1, arg2, arg3})
+	stub := fake.CaptureCheckInStub
+	fakeReturns := fake.captureCheckInReturns
+	fake.recordInvocation("CaptureCheckIn", []interface{}{arg1, arg2, arg3})
+	fake.captureCheckInMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *SentryClient) CaptureCheckInCallCount() int {
+	fake.captureCheckInMutex.RLock()
+	defer fake.captureCheckInMutex.RUnlock()
+	return len(fake.captureCheckInArgsForCall)
+}
+
+func (fake *SentryClient) CaptureCheckInCalls(stub func(*sentrya.CheckIn, *sentrya.MonitorConfig, sentrya.EventModifier) *sentrya.EventID) {
+	fake.captureCheckInMutex.Lock()
+	defer fake.captureCheckInMutex.Unlock()
+	fake.CaptureCheckInStub = stub
+}
+
+func (fake *SentryClient) CaptureCheckInArgsForCall(i int) (*sentrya.CheckIn, *sentrya.MonitorConfig, sentrya.EventModifier) {
+	fake.captureCheckInMutex.RLock()
+	defer fake.captureCheckInMutex.RUnlock()
+	argsForCall := fake.captureCheckInArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *SentryClient) CaptureCheckInReturns(result1 *sentrya.EventID) {
+	fake.captureCheckInMutex.Lock()
+	defer fake.captureCheckInMutex.Unlock()
+	fake.CaptureCheckInStub = nil
+	fake.captureCheckInReturns = struct {
+		result1 *sentrya.EventID
+	}{result1}
+}
+
+func (fake *SentryClient) CaptureCheckInReturnsOnCall(i int, result1 *sentrya.EventID) {
+	fake.captureCheckInMutex.Lock()
+	defer fake.captureCheckInMutex.Unlock()
+	fake.CaptureCheckInStub = nil
+	if fake.captureCheckInReturnsOnCall == nil {
+		fake.captureCheckInReturnsOnCall = make(map[int]struct {
+			result1 *sentrya.EventID
+		})
+	}
+	fake.captureCheckInReturnsOnCall[i] = struct {
+		result1 *sentrya.EventID
+	}{result1}
+}
+
 func (fake *SentryClient) CaptureException(arg1 error, arg2 *sentrya.EventHint, arg3 sentrya.EventModifier) *sentrya.EventID {
 	fake.captureExceptionMutex.Lock()
 	ret, specificReturn := fake.captureExceptionReturnsOnCall[len(fake.captureExceptionArgsForCall)]
@@ -301,6 +430,69 @@ func (fake *SentryClient) FlushReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *SentryClient) StartTransaction(arg1 context.Context, arg2 string, arg3 ...sentrya.SpanOption) *sentrya.Span {
+	fake.startTransactionMutex.Lock()
+	ret, specificReturn := fake.startTransactionReturnsOnCall[len(fake.startTransactionArgsForCall)]
+	fake.startTransactionArgsForCall = append(fake.startTransactionArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []sentrya.SpanOption
+	}{arg1, arg2, arg3})
+	stub := fake.StartTransactionStub
+	fakeReturns := fake.startTransactionReturns
+	fake.recordInvocation("StartTransaction", []interface{}{arg1, arg2, arg3})
+	fake.startTransactionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *SentryClient) StartTransactionCallCount() int {
+	fake.startTransactionMutex.RLock()
+	defer fake.startTransactionMutex.RUnlock()
+	return len(fake.startTransactionArgsForCall)
+}
+
+func (fake *SentryClient) StartTransactionCalls(stub func(context.Context, string, ...sentrya.SpanOption) *sentrya.Span) {
+	fake.startTransactionMutex.Lock()
+	defer fake.startTransactionMutex.Unlock()
+	fake.StartTransactionStub = stub
+}
+
+func (fake *SentryClient) StartTransactionArgsForCall(i int) (context.Context, string, []sentrya.SpanOption) {
+	fake.startTransactionMutex.RLock()
+	defer fake.startTransactionMutex.RUnlock()
+	argsForCall := fake.startTransactionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *SentryClient) StartTransactionReturns(result1 *sentrya.Span) {
+	fake.startTransactionMutex.Lock()
+	defer fake.startTransactionMutex.Unlock()
+	fake.StartTransactionStub = nil
+	fake.startTransactionReturns = struct {
+		result1 *sentrya.Span
+	}{result1}
+}
+
+func (fake *SentryClient) StartTransactionReturnsOnCall(i int, result1 *sentrya.Span) {
+	fake.startTransactionMutex.Lock()
+	defer fake.startTransactionMutex.Unlock()
+	fake.StartTransactionStub = nil
+	if fake.startTransactionReturnsOnCall == nil {
+		fake.startTransactionReturnsOnCall = make(map[int]struct {
+			result1 *sentrya.Span
+		})
+	}
+	fake.startTransactionReturnsOnCall[i] = struct {
+		result1 *sentrya.Span
+	}{result1}
+}
+
 func (fake *SentryClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
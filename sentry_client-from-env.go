@@ -0,0 +1,213 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	stdtime "time"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	// EnvDSN is the DSN of the Sentry project. Without DSN events are only logged.
+	EnvDSN = "SENTRY_DSN"
+	// EnvEnvironment is the environment of all events, e.g. prod.
+	EnvEnvironment = "SENTRY_ENVIRONMENT"
+	// EnvRelease is the release of all events, e.g. the version of the binary.
+	EnvRelease = "SENTRY_RELEASE"
+	// EnvProxy is a comma separated list of relay URLs requests to Sentry are sent to,
+	// see NewProxyRoundTripper.
+	EnvProxy = "SENTRY_PROXY"
+	// EnvSampleRate is the sample rate of error events above 0 and up to 1. The SDK treats
+	// 0 as 1, so 0 is rejected; unset EnvDSN to send no events.
+	EnvSampleRate = "SENTRY_SAMPLE_RATE"
+	// EnvTracesSampleRate is the sample rate of transactions between 0 and 1. A rate above 0
+	// enables tracing.
+	EnvTracesSampleRate = "SENTRY_TRACES_SAMPLE_RATE"
+	// EnvExcludeErrors is a newline separated list of regular expressions, since commas are
+	// part of regular expressions like \d{1,3}. Errors whose message matches any of them are
+	// not reported. Empty lines are ignored.
+	EnvExcludeErrors = "SENTRY_EXCLUDE_ERRORS"
+	// EnvTags is a comma separated list of key=value tags added to all events.
+	EnvTags = "SENTRY_TAGS"
+)
+
+// NewClientFromEnv creates a client configured by the environment variables EnvDSN,
+// EnvEnvironment, EnvRelease, EnvProxy, EnvSampleRate, EnvTracesSampleRate,
+// EnvExcludeErrors and EnvTags. The given option functions are applied after the
+// environment, so they can extend or override it.
+//
+// Without EnvDSN the returned client runs the complete pipeline but only logs the events
+// with glog instead of sending them, which allows running binaries locally without Sentry.
+// All invalid environment variables are returned as one error.
+func NewClientFromEnv(
+	ctx context.Context,
+	optionFns ...func(options *Options),
+) (Client, error) {
+	clientOptions, excludeErrors, err := parseEnv(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(ctx, err, "parse sentry environment failed")
+	}
+	if clientOptions.Dsn == "" {
+		glog.V(1).Infof("%s is not set => sentry events are only logged", EnvDSN)
+		clientOptions.Transport = &logTransport{}
+	}
+	return NewClientWithOptions(
+		ctx,
+		clientOptions,
+		append([]func(options *Options){
+			func(options *Options) {
+				options.ExcludeErrors = excludeErrors
+			},
+		}, optionFns...)...,
+	)
+}
+
+func parseEnv(ctx context.Context) (sentry.ClientOptions, ExcludeErrors, error) {
+	clientOptions := sentry.ClientOptions{
+		Dsn:         os.Getenv(EnvDSN),
+		Environment: os.Getenv(EnvEnvironment),
+		Release:     os.Getenv(EnvRelease),
+	}
+	var errs []error
+	var err error
+	if proxy := os.Getenv(EnvProxy); proxy != "" {
+		proxies := strings.Split(proxy, ",")
+		for i, value := range proxies {
+			proxies[i] = strings.TrimSpace(value)
+		}
+		clientOptions.HTTPTransport, err = NewProxyRoundTripperWithOptions(
+			ctx,
+			http.DefaultTransport,
+			func(options *ProxyRoundTripperOptions) {
+				options.URLs = proxies
+			},
+		)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(ctx, err, "invalid %s '%s'", EnvProxy, proxy))
+		}
+	}
+	if clientOptions.SampleRate, err = parseSampleRate(ctx, EnvSampleRate, 1); err != nil {
+		errs = append(errs, err)
+	} else if clientOptions.SampleRate == 0 {
+		errs = append(errs, errors.Errorf(
+			ctx,
+			"%s 0 would send all events, unset %s to send no events",
+			EnvSampleRate,
+			EnvDSN,
+		))
+	}
+	if clientOptions.TracesSampleRate, err = parseSampleRate(ctx, EnvTracesSampleRate, 0); err != nil {
+		errs = append(errs, err)
+	}
+	clientOptions.EnableTracing = clientOptions.TracesSampleRate > 0
+	if clientOptions.Tags, err = parseTags(ctx, os.Getenv(EnvTags)); err != nil {
+		errs = append(errs, err)
+	}
+	excludeErrors, err := parseExcludeErrors(ctx, os.Getenv(EnvExcludeErrors))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return sentry.ClientOptions{}, nil, errors.Join(errs...)
+	}
+	return clientOptions, excludeErrors, nil
+}
+
+// parseSampleRate returns the sample rate of the given environment variable or
+// defaultRate if it is not set.
+func parseSampleRate(ctx context.Context, name string, defaultRate float64) (float64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultRate, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.Wrapf(ctx, err, "parse %s '%s' failed", name, value)
+	}
+	if rate < 0 || rate > 1 {
+		return 0, errors.Errorf(ctx, "%s %v is not between 0 and 1", name, rate)
+	}
+	return rate, nil
+}
+
+func parseTags(ctx context.Context, value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	result := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		key, tagValue, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.Errorf(ctx, "%s entry '%s' is not key=value", EnvTags, entry)
+		}
+		result[key] = strings.TrimSpace(tagValue)
+	}
+	return result, nil
+}
+
+func parseExcludeErrors(ctx context.Context, value string) (ExcludeErrors, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var regexps []*regexp.Regexp
+	for _, pattern := range strings.Split(value, "\n") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "compile %s '%s' failed", EnvExcludeErrors, pattern)
+		}
+		regexps = append(regexps, re)
+	}
+	if len(regexps) == 0 {
+		return nil, nil
+	}
+	return ExcludeErrors{ExcludeErrorMessageRegexp(regexps...)}, nil
+}
+
+// logTransport is the sentry.Transport of clients without DSN. It logs events with glog
+// instead of sending them.
+type logTransport struct{}
+
+func (t *logTransport) Configure(options sentry.ClientOptions) {}
+
+func (t *logTransport) SendEvent(event *sentry.Event) {
+	switch {
+	case event.CheckIn != nil:
+		glog.V(2).Infof("sentry disabled, check-in %s of %s not sent",
+			event.CheckIn.Status, event.CheckIn.MonitorSlug)
+	case event.Type == "transaction":
+		glog.V(2).Infof("sentry disabled, transaction %s not sent", event.Transaction)
+	case len(event.Exception) > 0:
+		exception := event.Exception[len(event.Exception)-1]
+		glog.Warningf("sentry disabled, exception not sent: %s: %s tags: %v",
+			exception.Type, exception.Value, event.Tags)
+	default:
+		glog.Warningf("sentry disabled, message not sent: %s tags: %v", event.Message, event.Tags)
+	}
+}
+
+func (t *logTransport) Flush(timeout stdtime.Duration) bool {
+	return true
+}
+
+func (t *logTransport) FlushWithContext(ctx context.Context) bool {
+	return true
+}
+
+func (t *logTransport) Close() {}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+)
+
+var _ = Describe("NewClientFromEnv", func() {
+	var ctx context.Context
+	var events []*libsentry.Event
+	var client sentry.Client
+	var err error
+	BeforeEach(func() {
+		ctx = context.Background()
+		events = nil
+		for _, name := range []string{
+			sentry.EnvDSN,
+			sentry.EnvEnvironment,
+			sentry.EnvRelease,
+			sentry.EnvProxy,
+			sentry.EnvSampleRate,
+			sentry.EnvTracesSampleRate,
+			sentry.EnvExcludeErrors,
+			sentry.EnvTags,
+		} {
+			GinkgoT().Setenv(name, "")
+		}
+	})
+	newClient := func() {
+		client, err = sentry.NewClientFromEnv(ctx, func(options *sentry.Options) {
+			// collects the events passing the pipeline
+			options.ExcludeEvents = sentry.ExcludeEvents{
+				func(event *libsentry.Event, hint *libsentry.EventHint) bool {
+					events = append(events, event)
+					return false
+				},
+			}
+		})
+	}
+	Context("without dsn", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv(sentry.EnvEnvironment, "dev")
+			GinkgoT().Setenv(sentry.EnvRelease, "v1.2.3")
+			GinkgoT().Setenv(sentry.EnvTags, "service=billing, team = payments")
+			GinkgoT().Setenv(
+				sentry.EnvExcludeErrors,
+				"connection reset\n^context canceled$\nretry \\d{1,3} failed\n",
+			)
+			newClient()
+			Expect(err).To(BeNil())
+		})
+		AfterEach(func() {
+			Expect(client.Close()).To(Succeed())
+		})
+		It("captures events", func() {
+			Expect(client.CaptureException(stderrors.New("banana"), nil, nil)).NotTo(BeNil())
+			Expect(events).To(HaveLen(1))
+		})
+		It("sets environment and release", func() {
+			client.CaptureMessage("banana", nil, nil)
+			Expect(events[0].Environment).To(Equal("dev"))
+			Expect(events[0].Release).To(Equal("v1.2.3"))
+		})
+		It("adds the tags", func() {
+			client.CaptureMessage("banana", nil, nil)
+			Expect(events[0].Tags).To(HaveKeyWithValue("service", "billing"))
+			Expect(events[0].Tags).To(HaveKeyWithValue("team", "payments"))
+		})
+		It("excludes matching errors", func() {
+			resetErr := stderrors.New("read: connection reset by peer")
+			Expect(client.CaptureException(resetErr, nil, nil)).To(BeNil())
+			Expect(client.CaptureException(context.Canceled, nil, nil)).To(BeNil())
+			Expect(events).To(BeEmpty())
+		})
+		It("excludes errors matching regular expressions with commas", func() {
+			Expect(client.CaptureException(stderrors.New("retry 12 failed"), nil, nil)).To(BeNil())
+			Expect(client.CaptureException(stderrors.New("retry 1234 failed"), nil, nil)).NotTo(BeNil())
+			Expect(events).To(HaveLen(1))
+		})
+		It("does not sample transactions", func() {
+			transaction := client.StartTransaction(ctx, "job")
+			Expect(transaction.Sampled).To(Equal(libsentry.SampledFalse))
+			transaction.Finish()
+		})
+	})
+	It("enables tracing with traces sample rate", func() {
+		GinkgoT().Setenv(sentry.EnvTracesSampleRate, "1")
+		newClient()
+		Expect(err).To(BeNil())
+		transaction := client.StartTransaction(ctx, "job")
+		Expect(transaction.Sampled).To(Equal(libsentry.SampledTrue))
+		transaction.Finish()
+	})
+	It("accepts a proxy", func() {
+		GinkgoT().Setenv(sentry.EnvProxy, "http://relay-0:8080, http://relay-1:8080")
+		newClient()
+		Expect(err).To(BeNil())
+	})
+	DescribeTable("rejects invalid values",
+		func(name string, value string) {
+			GinkgoT().Setenv(name, value)
+			newClient()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(name))
+		},
+		Entry("sample rate", sentry.EnvSampleRate, "banana"),
+		Entry("sample rate out of range", sentry.EnvSampleRate, "1.5"),
+		Entry("sample rate 0", sentry.EnvSampleRate, "0"),
+		Entry("traces sample rate", sentry.EnvTracesSampleRate, "-1"),
+		Entry("tags", sentry.EnvTags, "service"),
+		Entry("exclude errors", sentry.EnvExcludeErrors, "(banana"),
+		Entry("proxy", sentry.EnvProxy, "sentry-proxy"),
+	)
+	It("reports all invalid values", func() {
+		GinkgoT().Setenv(sentry.EnvSampleRate, "banana")
+		GinkgoT().Setenv(sentry.EnvTags, "service")
+		newClient()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(sentry.EnvSampleRate))
+		Expect(err.Error()).To(ContainSubstring(sentry.EnvTags))
+	})
+})
@@ -28,9 +28,11 @@ package sentry
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
 	stdtime "time"
 
 	"github.com/bborbe/errors"
@@ -40,6 +42,9 @@ import (
 
 //counterfeiter:generate -o mocks/sentry-client.go --fake-name SentryClient . Client
 
+// DefaultCloseTimeout is the default of Options.CloseTimeout.
+const DefaultCloseTimeout = 2 * stdtime.Second
+
 // Client provides an enhanced interface for interacting with Sentry error tracking.
 // It wraps the official Sentry Go SDK and adds automatic tag enrichment from context
 // and errors, configurable error filtering, and enhanced integration with github.com/bborbe/errors.
@@ -54,82 +59,319 @@ type Client interface {
 		hint *sentry.EventHint,
 		scope sentry.EventModifier,
 	) *sentry.EventID
+	// AddBreadcrumb records a breadcrumb in the scope of the given context, e.g. created by
+	// NewHTTPMiddleware, WithTag or WithBreadcrumb. Events captured with this context attach
+	// the recorded breadcrumbs. Breadcrumbs of a context without scope are dropped, because
+	// they would be attached to unrelated events.
+	AddBreadcrumb(ctx context.Context, breadcrumb *sentry.Breadcrumb)
+	// CaptureCheckIn sends a check-in of a Sentry Crons monitor and upserts the monitor if
+	// monitorConfig is set. It returns the id of the check-in.
+	CaptureCheckIn(
+		checkIn *sentry.CheckIn,
+		monitorConfig *sentry.MonitorConfig,
+		scope sentry.EventModifier,
+	) *sentry.EventID
+	// StartTransaction starts a transaction sent by this client when it is finished. The
+	// scope of the given context is inherited. Spans started with StartChildSpan from the
+	// context of the returned span become children of the transaction. Sampling follows
+	// ClientOptions.EnableTracing, TracesSampleRate and TracesSampler.
+	StartTransaction(
+		ctx context.Context,
+		name string,
+		options ...sentry.SpanOption,
+	) *sentry.Span
 	Flush(timeout stdtime.Duration) bool
+	// Close flushes pending events for at most Options.CloseTimeout and closes the client.
+	// It returns an *UndeliveredEventsError if not all events are delivered. Captures after
+	// Close are dropped. Calling Close again returns the result of the first call.
 	io.Closer
 }
 
 // NewClient creates a new Sentry client with enhanced functionality including automatic
 // tag enrichment and error filtering. It accepts standard Sentry ClientOptions and optional
 // ExcludeError functions to filter out specific errors from being sent to Sentry.
+// Wrapped and joined errors are reported as chained exceptions, limited to
+// ClientOptions.MaxErrorDepth per event.
 //
 // WARNING: Do not pass sensitive information (passwords, API keys, PII, tokens) in hint.Data,
 // context data, or error data as these will be sent to Sentry as tags and may be stored or
-// logged externally.
+// logged externally. Use NewClientWithOptions with Options.Scrubber to filter them.
 func NewClient(
 	ctx context.Context,
 	clientOptions sentry.ClientOptions,
 	excludeErrors ...ExcludeError,
 ) (Client, error) {
+	return NewClientWithOptions(ctx, clientOptions, func(options *Options) {
+		options.ExcludeErrors = excludeErrors
+	})
+}
+
+// Options configures the additional behaviour of the client created by NewClientWithOptions.
+type Options struct {
+	// ExcludeErrors filters errors before they are captured.
+	ExcludeErrors ExcludeErrors
+	// ExcludeErrorHints filters errors with access to the hint before they are captured.
+	ExcludeErrorHints ExcludeErrorHints
+	// ExcludeEvents drops exception and message events after tag enrichment.
+	ExcludeEvents ExcludeEvents
+	// Scrubber removes secrets and personal data from events after tag enrichment.
+	// Nil disables scrubbing, see NewScrubber.
+	Scrubber Scrubber
+	// TagOverflow defines how tag values longer than MaxTagValueLength are handled.
+	// Defaults to TagOverflowTruncate.
+	TagOverflow TagOverflow
+	// DataRouter decides which data of context, error and hint becomes a tag and which
+	// is attached to context DataContext. Defaults to DataRouteAllTags.
+	DataRouter DataRouter
+	// DataPrecedence is the order in which tag sources are applied, later sources have
+	// higher precedence. Defaults to DefaultDataPrecedence.
+	DataPrecedence []DataSource
+	// TagCollision defines how a tag set by multiple sources with different values is
+	// resolved. All values of a collision are recorded in context TagCollisionsContext.
+	// Defaults to TagCollisionOverwrite.
+	TagCollision TagCollision
+	// ErrorStacktraces attaches the stacktraces recorded by the errors of the chain, e.g. by
+	// errors.Wrap, instead of the stacktrace of the CaptureException call site.
+	// Defaults to true.
+	ErrorStacktraces bool
+	// InAppModules marks stack frames of modules with any of the given prefixes as in app
+	// and all other frames as not in app. Empty keeps the marking of the SDK.
+	InAppModules []string
+	// CloseTimeout is the maximum duration Close waits for the delivery of pending events.
+	// Defaults to DefaultCloseTimeout.
+	CloseTimeout stdtime.Duration
+	// Metrics records captures, exclusions, dropped events, transport errors and flushes.
+	// Nil disables metrics, see NewMetrics.
+	Metrics Metrics
+}
+
+// NewClientWithOptions creates a new Sentry client like NewClient, configured by the given
+// option functions.
+//
+//	client, err := sentry.NewClientWithOptions(ctx, clientOptions, func(options *sentry.Options) {
+//	    options.ExcludeErrorHints = sentry.ExcludeErrorHints{
+//	        sentry.ExcludeErrorHintWithData("expected", sentry.DataValueEquals("true")),
+//	    }
+//	})
+func NewClientWithOptions(
+	ctx context.Context,
+	clientOptions sentry.ClientOptions,
+	optionFns ...func(options *Options),
+) (Client, error) {
+	options := Options{
+		TagOverflow:      TagOverflowTruncate,
+		DataRouter:       DataRouteAllTags(),
+		DataPrecedence:   DefaultDataPrecedence,
+		TagCollision:     TagCollisionOverwrite,
+		ErrorStacktraces: true,
+		CloseTimeout:     DefaultCloseTimeout,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	metrics := options.Metrics
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
+	pending := newPendingEvents(metrics)
+	clientOptions = withRoundTripper(clientOptions, options.Metrics, pending)
 	newClient, err := sentry.NewClient(clientOptions)
 	if err != nil {
 		return nil, errors.Wrap(ctx, err, "create sentry client failed")
 	}
-	newClient.AddEventProcessor(enrichEventTags)
-	return &client{
-		client:        newClient,
-		excludeErrors: excludeErrors,
-	}, nil
+	result := &client{
+		client:            newClient,
+		excludeErrors:     options.ExcludeErrors,
+		excludeErrorHints: options.ExcludeErrorHints,
+		exceptionModifier: newExceptionChainModifier(options.ErrorStacktraces),
+		closeTimeout:      options.CloseTimeout,
+		metrics:           metrics,
+		pending:           pending,
+	}
+	newClient.AddEventProcessor(newEnrichEventTags(options))
+	if options.Scrubber != nil {
+		newClient.AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			return options.Scrubber.ScrubEvent(event)
+		})
+	}
+	newClient.AddEventProcessor(newTagNormalizer(options.TagOverflow))
+	if len(options.ExcludeEvents) > 0 {
+		newClient.AddEventProcessor(
+			newExcludeEventProcessor(options.ExcludeEvents, metrics, result.markDropped),
+		)
+	}
+	if len(options.InAppModules) > 0 {
+		newClient.AddEventProcessor(newInAppProcessor(options.InAppModules))
+	}
+	newClient.AddEventProcessor(result.dropAfterClose)
+	newClient.AddEventProcessor(result.trackPending)
+	return result, nil
 }
 
-func enrichEventTags(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
-	if event.Tags == nil {
+// newEnrichEventTags creates an event processor that attaches the data of context, error
+// and hint to the event as routed by options.DataRouter. Tags are applied in the order of
+// options.DataPrecedence and conflicts are resolved by options.TagCollision.
+func newEnrichEventTags(options Options) sentry.EventProcessor {
+	router := options.DataRouter
+	if router == nil {
+		router = DataRouteAllTags()
+	}
+	precedence := options.DataPrecedence
+	if !slices.Contains(precedence, DataSourceEvent) {
+		precedence = append([]DataSource{DataSourceEvent}, precedence...)
+	}
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if hint == nil {
+			// events like check-ins are captured without hint
+			hint = &sentry.EventHint{}
+		}
+		data := make(map[DataSource]map[string]any, len(precedence))
+		for _, source := range precedence {
+			data[source] = dataFromSource(source, event, hint)
+		}
+		merger := &tagMerger{
+			event:      event,
+			collision:  options.TagCollision,
+			router:     router,
+			sources:    make(map[string]DataSource),
+			collisions: make(sentry.Context),
+		}
 		event.Tags = make(map[string]string)
+		for _, source := range precedence {
+			merger.merge(source, data[source])
+		}
+		if len(merger.collisions) > 0 {
+			if event.Contexts == nil {
+				event.Contexts = make(map[string]sentry.Context)
+			}
+			event.Contexts[TagCollisionsContext] = merger.collisions
+		}
+		return event
 	}
-	addContextTags(event, hint)
-	addErrorTags(event, hint)
-	addHintDataTags(event, hint)
-	return event
 }
 
-func addContextTags(event *sentry.Event, hint *sentry.EventHint) {
-	if hint.Context == nil {
-		return
+type client struct {
+	client            *sentry.Client
+	excludeErrors     ExcludeErrors
+	excludeErrorHints ExcludeErrorHints
+	exceptionModifier EventModifier
+	closeTimeout      stdtime.Duration
+	metrics           Metrics
+
+	// mux is held for reading by captures and for writing when closing, so no capture
+	// reaches the transport after Close started
+	mux       sync.RWMutex
+	closed    atomic.Bool
+	closeOnce sync.Once
+	closeErr  error
+	// pending contains the events passed to the transport whose delivery is not confirmed
+	pending *pendingEvents
+	dropped atomic.Int64
+	// captures contains the captureState of the running captures by their hint
+	captures sync.Map
+}
+
+// captureState is shared by a capture and the event processors of its event.
+type captureState struct {
+	// eventID is the id of the event passed to the transport
+	eventID sentry.EventID
+	// dropped is true if the event was dropped by the client, e.g. by ExcludeEvents
+	dropped bool
+}
+
+func (c *client) Flush(timeout stdtime.Duration) bool {
+	snapshot := c.pending.snapshot()
+	if !c.flush(timeout) {
+		return false
+	}
+	c.pending.removeSnapshot(snapshot)
+	return true
+}
+
+// flush flushes the sentry client and records the duration.
+func (c *client) flush(timeout stdtime.Duration) bool {
+	start := stdtime.Now()
+	delivered := c.client.Flush(timeout)
+	c.metrics.FlushDurationObserve(stdtime.Since(start), delivered)
+	return delivered
+}
+
+// capture calls the given function with a copy of the hint unless the client is closed.
+// The copy identifies the capture in the event processors.
+func (c *client) capture(
+	kind string,
+	hint *sentry.EventHint,
+	fn func(hint *sentry.EventHint) *sentry.EventID,
+) *sentry.EventID {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	if c.closed.Load() {
+		glog.Warningf("sentry client is closed => drop event (%d dropped)", c.dropped.Add(1))
+		c.metrics.DroppedInc("closed")
+		return nil
 	}
-	for k, v := range errors.DataFromContext(hint.Context) {
-		event.Tags[k] = fmt.Sprintf("%v", v)
+	captureHint := &sentry.EventHint{}
+	if hint != nil {
+		*captureHint = *hint
 	}
+	state := &captureState{}
+	c.captures.Store(captureHint, state)
+	defer c.captures.Delete(captureHint)
+	eventID := fn(captureHint)
+	if eventID == nil {
+		if state.eventID != "" {
+			// dropped after the event processors, e.g. by BeforeSend
+			c.pending.remove(state.eventID)
+		}
+		if !state.dropped {
+			c.metrics.EventIDNilInc(kind)
+		}
+		return nil
+	}
+	return eventID
 }
 
-func addErrorTags(event *sentry.Event, hint *sentry.EventHint) {
-	if hint.OriginalException == nil {
-		return
+// captureState returns the state of the capture of the given hint or nil for events not
+// captured by capture, e.g. transactions.
+func (c *client) captureState(hint *sentry.EventHint) *captureState {
+	if hint == nil {
+		return nil
 	}
-	for k, v := range errors.DataFromError(hint.OriginalException) {
-		event.Tags[k] = fmt.Sprintf("%v", v)
+	state, ok := c.captures.Load(hint)
+	if !ok {
+		return nil
 	}
+	return state.(*captureState)
 }
 
-func addHintDataTags(event *sentry.Event, hint *sentry.EventHint) {
-	switch data := hint.Data.(type) {
-	case map[string]any:
-		for k, v := range data {
-			if v == nil {
-				continue
-			}
-			event.Tags[k] = fmt.Sprintf("%v", v)
-		}
-	case map[string]string:
-		maps.Copy(event.Tags, data)
+// markDropped marks the capture of the given hint as dropped by the client, so it is not
+// counted by Metrics.EventIDNilInc.
+func (c *client) markDropped(hint *sentry.EventHint) {
+	if state := c.captureState(hint); state != nil {
+		state.dropped = true
 	}
 }
 
-type client struct {
-	client        *sentry.Client
-	excludeErrors ExcludeErrors
+// trackPending is the last event processor. It adds the event to the pending events.
+func (c *client) trackPending(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	c.pending.add(event.EventID)
+	if state := c.captureState(hint); state != nil {
+		state.eventID = event.EventID
+	}
+	return event
 }
 
-func (c *client) Flush(timeout stdtime.Duration) bool {
-	return c.client.Flush(timeout)
+// dropAfterClose is an event processor dropping events sent after Close, e.g. by
+// transactions finished after the client was closed.
+func (c *client) dropAfterClose(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	if c.closed.Load() {
+		dropped := c.dropped.Add(1)
+		glog.Warningf("sentry client is closed => drop %s event (%d dropped)", event.Type, dropped)
+		c.metrics.DroppedInc("closed")
+		c.markDropped(hint)
+		return nil
+	}
+	return event
 }
 
 func (c *client) CaptureMessage(
@@ -137,7 +379,16 @@ func (c *client) CaptureMessage(
 	hint *sentry.EventHint,
 	scope sentry.EventModifier,
 ) *sentry.EventID {
-	eventID := c.client.CaptureMessage(message, hint, scope)
+	c.metrics.CaptureInc(CaptureKindMessage)
+	if hint == nil {
+		hint = &sentry.EventHint{}
+	}
+	if hint.OriginalException != nil && c.isExcluded(hint.OriginalException, hint) {
+		return nil
+	}
+	eventID := c.capture(CaptureKindMessage, hint, func(hint *sentry.EventHint) *sentry.EventID {
+		return c.client.CaptureMessage(message, hint, withContextScope(hint, scope))
+	})
 	if eventID != nil {
 		glog.V(2).Infof("capture sentry message with id %s", *eventID)
 	} else {
@@ -151,20 +402,25 @@ func (c *client) CaptureException(
 	hint *sentry.EventHint,
 	scope sentry.EventModifier,
 ) *sentry.EventID {
-	if c.excludeErrors.IsExcluded(err) {
-		glog.V(4).Infof("capture error %v is excluded => skip", err)
+	c.metrics.CaptureInc(CaptureKindException)
+	if hint == nil {
+		hint = &sentry.EventHint{}
+	}
+	if c.isExcluded(err, hint) {
 		return nil
 	}
 	if scope == nil {
 		scope = sentry.NewScope()
 	}
-	if hint == nil {
-		hint = &sentry.EventHint{}
-	}
 	if hint.OriginalException == nil {
 		hint.OriginalException = err
 	}
-	eventID := c.client.CaptureException(err, hint, scope)
+	// the exception modifier runs before the scope, so modifiers of the caller can still
+	// replace stacktraces
+	modifier := EventModifierList{c.exceptionModifier, withContextScope(hint, scope)}
+	eventID := c.capture(CaptureKindException, hint, func(hint *sentry.EventHint) *sentry.EventID {
+		return c.client.CaptureException(err, hint, modifier)
+	})
 	if eventID != nil {
 		glog.V(3).Infof("capture sentry exception with id %s", *eventID)
 	} else {
@@ -173,7 +429,169 @@ func (c *client) CaptureException(
 	return eventID
 }
 
+func (c *client) CaptureCheckIn(
+	checkIn *sentry.CheckIn,
+	monitorConfig *sentry.MonitorConfig,
+	scope sentry.EventModifier,
+) *sentry.EventID {
+	c.metrics.CaptureInc(CaptureKindCheckIn)
+	checkInID := c.capture(CaptureKindCheckIn, nil, func(*sentry.EventHint) *sentry.EventID {
+		return c.client.CaptureCheckIn(checkIn, monitorConfig, scope)
+	})
+	if checkInID != nil {
+		glog.V(3).Infof("capture sentry check-in %s with id %s", checkIn.Status, *checkInID)
+	} else {
+		glog.V(2).Infof("capture sentry check-in failed: checkInID is nil")
+	}
+	return checkInID
+}
+
+func (c *client) StartTransaction(
+	ctx context.Context,
+	name string,
+	options ...sentry.SpanOption,
+) *sentry.Span {
+	hub := hubFromContext(ctx)
+	if hub != nil {
+		hub = hub.Clone()
+		hub.BindClient(c.client)
+	} else {
+		hub = sentry.NewHub(c.client, sentry.NewScope())
+	}
+	if c.closed.Load() {
+		// transactions of a closed client are never sent
+		options = append(options, sentry.WithSpanSampled(sentry.SampledFalse))
+	}
+	return sentry.StartTransaction(sentry.SetHubOnContext(ctx, hub), name, options...)
+}
+
+func (c *client) isExcluded(err error, hint *sentry.EventHint) bool {
+	if excludeError, ok := c.excludeErrors.match(err); ok {
+		glog.V(4).Infof("capture error %v is excluded => skip", err)
+		c.metrics.ExcludedInc(matcherName(excludeError))
+		return true
+	}
+	if excludeErrorHint, ok := c.excludeErrorHints.match(err, hint); ok {
+		glog.V(4).Infof("capture error %v is excluded by hint => skip", err)
+		c.metrics.ExcludedInc(matcherName(excludeErrorHint))
+		return true
+	}
+	return false
+}
+
+// withContextScope prepends the scope of the hub stored in hint.Context, e.g. by
+// NewHTTPMiddleware, so the given scope can override it.
+// If the context contains a span, e.g. of StartTransaction, the event keeps its trace.
+func withContextScope(
+	hint *sentry.EventHint,
+	scope sentry.EventModifier,
+) sentry.EventModifier {
+	hub := hubFromContext(hint.Context)
+	if hub == nil {
+		return scope
+	}
+	contextScope := hub.Scope()
+	if scope == nil {
+		return contextScope
+	}
+	if contextScope.GetSpan() == nil {
+		return EventModifierList{contextScope, scope}
+	}
+	return EventModifierFunc(
+		func(event *sentry.Event, hint *sentry.EventHint, client *sentry.Client) *sentry.Event {
+			event = contextScope.ApplyToEvent(event, hint, client)
+			if event == nil {
+				return nil
+			}
+			// a scope without span replaces the trace with its own propagation context
+			trace := event.Contexts["trace"]
+			event = scope.ApplyToEvent(event, hint, client)
+			if event != nil && trace != nil {
+				event.Contexts["trace"] = trace
+			}
+			return event
+		},
+	)
+}
+
+// withRoundTripper confirms the delivery of pending events and counts the failed requests
+// of the HTTP transport if metrics are given. Without HTTPTransport the SDK configures its
+// own transport for proxies and certificates, which is kept in that case. Events are then
+// only confirmed by a successful Flush.
+func withRoundTripper(
+	clientOptions sentry.ClientOptions,
+	metrics Metrics,
+	pending *pendingEvents,
+) sentry.ClientOptions {
+	roundTripper := clientOptions.HTTPTransport
+	if roundTripper == nil {
+		sdkTransport := clientOptions.HTTPProxy != "" ||
+			clientOptions.HTTPSProxy != "" ||
+			clientOptions.CaCerts != nil
+		if sdkTransport {
+			glog.V(2).Infof("sentry requests are not tracked with proxy or ca certs")
+			return clientOptions
+		}
+		roundTripper = http.DefaultTransport
+	}
+	if metrics != nil {
+		roundTripper = newMetricsRoundTripper(roundTripper, metrics)
+	}
+	clientOptions.HTTPTransport = newPendingRoundTripper(roundTripper, pending)
+	return clientOptions
+}
+
+func hubFromContext(ctx context.Context) *sentry.Hub {
+	if ctx == nil {
+		return nil
+	}
+	return sentry.GetHubFromContext(ctx)
+}
+
+func (c *client) AddBreadcrumb(ctx context.Context, breadcrumb *sentry.Breadcrumb) {
+	if breadcrumb == nil {
+		return
+	}
+	hub := hubFromContext(ctx)
+	if hub == nil {
+		glog.V(2).Infof("breadcrumb %q of context without scope dropped => skip", breadcrumb.Message)
+		return
+	}
+	options := c.client.Options()
+	limit := options.MaxBreadcrumbs
+	switch {
+	case limit < 0:
+		return
+	case limit == 0:
+		limit = MaxContextBreadcrumbs
+	}
+	if options.BeforeBreadcrumb != nil {
+		breadcrumb = options.BeforeBreadcrumb(breadcrumb, &sentry.BreadcrumbHint{
+			"context": ctx,
+		})
+		if breadcrumb == nil {
+			glog.V(4).Infof("breadcrumb dropped by BeforeBreadcrumb => skip")
+			return
+		}
+	}
+	hub.Scope().AddBreadcrumb(breadcrumb, limit)
+}
+
 func (c *client) Close() error {
-	c.client.Flush(2 * stdtime.Second)
-	return nil
+	c.closeOnce.Do(func() {
+		c.mux.Lock()
+		c.closed.Store(true)
+		c.mux.Unlock()
+		if !c.flush(c.closeTimeout) {
+			c.closeErr = &UndeliveredEventsError{
+				Pending: c.pending.count(),
+				Timeout: c.closeTimeout,
+			}
+			glog.Warningf("close sentry client failed: %v", c.closeErr)
+		}
+		c.client.Close()
+		// undelivered events are lost after Close
+		c.pending.clear()
+	})
+	return c.closeErr
 }
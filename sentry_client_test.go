@@ -0,0 +1,260 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+)
+
+var _ = Describe("Client AddBreadcrumb", func() {
+	var ctx context.Context
+	var clientOptions libsentry.ClientOptions
+	var events []*libsentry.Event
+	var client sentry.Client
+	BeforeEach(func() {
+		ctx = context.Background()
+		events = nil
+		clientOptions = libsentry.ClientOptions{
+			MaxBreadcrumbs: 3,
+			BeforeSend: func(event *libsentry.Event, hint *libsentry.EventHint) *libsentry.Event {
+				events = append(events, event)
+				return nil
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		var err error
+		client, err = sentry.NewClient(ctx, clientOptions)
+		Expect(err).To(BeNil())
+	})
+	capture := func(ctx context.Context) *libsentry.Event {
+		events = nil
+		client.CaptureException(stderrors.New("banana"), &libsentry.EventHint{Context: ctx}, nil)
+		Expect(events).To(HaveLen(1))
+		return events[0]
+	}
+	messages := func(event *libsentry.Event) []string {
+		var result []string
+		for _, breadcrumb := range event.Breadcrumbs {
+			result = append(result, breadcrumb.Message)
+		}
+		return result
+	}
+	It("drops breadcrumbs of a context without scope", func() {
+		client.AddBreadcrumb(ctx, &libsentry.Breadcrumb{Message: "first"})
+		Expect(capture(ctx).Breadcrumbs).To(BeEmpty())
+		Expect(capture(sentry.WithTag(ctx, "request", "1")).Breadcrumbs).To(BeEmpty())
+	})
+	It("keeps only the latest breadcrumbs", func() {
+		ctx = sentry.WithTag(ctx, "request", "1")
+		for i := 0; i < 5; i++ {
+			client.AddBreadcrumb(ctx, &libsentry.Breadcrumb{Message: fmt.Sprintf("crumb %d", i)})
+		}
+		Expect(messages(capture(ctx))).To(Equal([]string{"crumb 2", "crumb 3", "crumb 4"}))
+	})
+	It("records breadcrumbs in the scope of the context", func() {
+		requestCtx := sentry.WithTag(ctx, "request", "1")
+		client.AddBreadcrumb(requestCtx, &libsentry.Breadcrumb{Message: "request 1"})
+		otherCtx := sentry.WithTag(ctx, "request", "2")
+		Expect(messages(capture(requestCtx))).To(Equal([]string{"request 1"}))
+		Expect(messages(capture(otherCtx))).To(BeEmpty())
+		Expect(messages(capture(ctx))).To(BeEmpty())
+	})
+	Context("BeforeBreadcrumb", func() {
+		BeforeEach(func() {
+			clientOptions.BeforeBreadcrumb = func(
+				breadcrumb *libsentry.Breadcrumb,
+				hint *libsentry.BreadcrumbHint,
+			) *libsentry.Breadcrumb {
+				if breadcrumb.Category == "debug" {
+					return nil
+				}
+				return breadcrumb
+			}
+		})
+		It("drops filtered breadcrumbs", func() {
+			ctx = sentry.WithTag(ctx, "request", "1")
+			client.AddBreadcrumb(ctx, &libsentry.Breadcrumb{Message: "keep"})
+			client.AddBreadcrumb(ctx, &libsentry.Breadcrumb{Message: "drop", Category: "debug"})
+			Expect(messages(capture(ctx))).To(Equal([]string{"keep"}))
+		})
+	})
+	Context("disabled", func() {
+		BeforeEach(func() {
+			clientOptions.MaxBreadcrumbs = -1
+		})
+		It("records nothing", func() {
+			ctx = sentry.WithTag(ctx, "request", "1")
+			client.AddBreadcrumb(ctx, &libsentry.Breadcrumb{Message: "first"})
+			Expect(capture(ctx).Breadcrumbs).To(BeEmpty())
+		})
+	})
+})
+
+// flushTransport is a sentry.Transport whose Flush returns the configured result.
+type flushTransport struct {
+	delivered atomic.Bool
+	sent      atomic.Int64
+	closed    atomic.Bool
+}
+
+func (t *flushTransport) Configure(options libsentry.ClientOptions) {}
+
+func (t *flushTransport) SendEvent(event *libsentry.Event) {
+	t.sent.Add(1)
+}
+
+func (t *flushTransport) Flush(timeout time.Duration) bool {
+	return t.delivered.Load()
+}
+
+func (t *flushTransport) FlushWithContext(ctx context.Context) bool {
+	return t.delivered.Load()
+}
+
+func (t *flushTransport) Close() {
+	t.closed.Store(true)
+}
+
+var _ = Describe("Client Close", func() {
+	var ctx context.Context
+	var transport *flushTransport
+	var client sentry.Client
+	BeforeEach(func() {
+		ctx = context.Background()
+		transport = &flushTransport{}
+		transport.delivered.Store(true)
+		var err error
+		client, err = sentry.NewClientWithOptions(
+			ctx,
+			libsentry.ClientOptions{
+				Transport:     transport,
+				EnableTracing: true,
+			},
+			func(options *sentry.Options) {
+				options.CloseTimeout = 10 * time.Millisecond
+			},
+		)
+		Expect(err).To(BeNil())
+	})
+	It("returns nil if all events are delivered", func() {
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Close()).To(BeNil())
+	})
+	It("closes the transport", func() {
+		Expect(client.Close()).To(BeNil())
+		Expect(transport.closed.Load()).To(BeTrue())
+	})
+	Context("undelivered", func() {
+		var err error
+		BeforeEach(func() {
+			transport.delivered.Store(false)
+			client.CaptureException(stderrors.New("banana"), nil, nil)
+			client.CaptureMessage("banana", nil, nil)
+			err = client.Close()
+		})
+		It("returns UndeliveredEventsError", func() {
+			var undelivered *sentry.UndeliveredEventsError
+			Expect(stderrors.As(err, &undelivered)).To(BeTrue())
+			Expect(undelivered.Pending).To(Equal(2))
+			Expect(undelivered.Timeout).To(Equal(10 * time.Millisecond))
+		})
+		It("returns the same result on repeated calls", func() {
+			Expect(client.Close()).To(BeIdenticalTo(err))
+		})
+	})
+	It("does not count events delivered by Flush", func() {
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Flush(time.Second)).To(BeTrue())
+		transport.delivered.Store(false)
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		var undelivered *sentry.UndeliveredEventsError
+		Expect(stderrors.As(client.Close(), &undelivered)).To(BeTrue())
+		Expect(undelivered.Pending).To(Equal(1))
+	})
+	Context("after close", func() {
+		BeforeEach(func() {
+			Expect(client.Close()).To(BeNil())
+		})
+		It("drops captures", func() {
+			Expect(client.CaptureException(stderrors.New("banana"), nil, nil)).To(BeNil())
+			Expect(client.CaptureMessage("banana", nil, nil)).To(BeNil())
+			Expect(transport.sent.Load()).To(Equal(int64(0)))
+		})
+		It("drops check-ins", func() {
+			checkIn := &libsentry.CheckIn{MonitorSlug: "job", Status: libsentry.CheckInStatusOK}
+			Expect(client.CaptureCheckIn(checkIn, nil, nil)).To(BeNil())
+			Expect(transport.sent.Load()).To(Equal(int64(0)))
+		})
+		It("does not sample transactions", func() {
+			transaction := client.StartTransaction(ctx, "job")
+			Expect(transaction.Sampled).To(Equal(libsentry.SampledFalse))
+			transaction.Finish()
+			Expect(transport.sent.Load()).To(Equal(int64(0)))
+		})
+	})
+	Context("http transport", func() {
+		var server *httptest.Server
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				if strings.Contains(string(body), "slow") {
+					// longer than CloseTimeout
+					time.Sleep(250 * time.Millisecond)
+				}
+				resp.WriteHeader(http.StatusOK)
+			}))
+			var err error
+			client, err = sentry.NewClientWithOptions(
+				ctx,
+				libsentry.ClientOptions{
+					Dsn: strings.Replace(server.URL, "://", "://public@", 1) + "/1",
+				},
+				func(options *sentry.Options) {
+					options.CloseTimeout = 50 * time.Millisecond
+				},
+			)
+			Expect(err).To(BeNil())
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("counts only events whose request did not complete", func() {
+			client.CaptureMessage("fast", nil, nil)
+			client.CaptureMessage("fast", nil, nil)
+			Expect(client.Flush(time.Second)).To(BeTrue())
+			client.CaptureMessage("fast", nil, nil)
+			client.CaptureMessage("slow", nil, nil)
+
+			var undelivered *sentry.UndeliveredEventsError
+			Expect(stderrors.As(client.Close(), &undelivered)).To(BeTrue())
+			Expect(undelivered.Pending).To(Equal(1))
+		})
+	})
+	It("drops transactions finished after close", func() {
+		transaction := client.StartTransaction(
+			ctx,
+			"job",
+			libsentry.WithSpanSampled(libsentry.SampledTrue),
+		)
+		Expect(client.Close()).To(BeNil())
+		transaction.Finish()
+		Expect(transport.sent.Load()).To(Equal(int64(0)))
+	})
+})
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// MaxContextBreadcrumbs is the maximum number of breadcrumbs kept by WithBreadcrumb.
+const MaxContextBreadcrumbs = 100
+
+// WithTag returns a context whose scope contains the given tag. The client merges the scope
+// into every event captured with this context as hint.Context:
+//
+//	ctx = sentry.WithTag(ctx, "tenant", tenant)
+//	sentryClient.CaptureException(err, &sentry.EventHint{Context: ctx}, nil)
+func WithTag(ctx context.Context, key string, value string) context.Context {
+	return withScope(ctx, func(scope *sentry.Scope) {
+		scope.SetTag(key, value)
+	})
+}
+
+// WithTags returns a context whose scope contains the given tags.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	return withScope(ctx, func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+	})
+}
+
+// WithUser returns a context whose scope contains the given user.
+func WithUser(ctx context.Context, user sentry.User) context.Context {
+	return withScope(ctx, func(scope *sentry.Scope) {
+		scope.SetUser(user)
+	})
+}
+
+// WithLevel returns a context whose scope sets the level of events.
+func WithLevel(ctx context.Context, level sentry.Level) context.Context {
+	return withScope(ctx, func(scope *sentry.Scope) {
+		scope.SetLevel(level)
+	})
+}
+
+// WithFingerprint returns a context whose scope sets the fingerprint of events.
+func WithFingerprint(ctx context.Context, fingerprint ...string) context.Context {
+	return withScope(ctx, func(scope *sentry.Scope) {
+		scope.SetFingerprint(fingerprint)
+	})
+}
+
+// WithBreadcrumb returns a context whose scope contains the given breadcrumb in addition
+// to the breadcrumbs of the parent context, up to MaxContextBreadcrumbs.
+func WithBreadcrumb(ctx context.Context, breadcrumb *sentry.Breadcrumb) context.Context {
+	return withScope(ctx, func(scope *sentry.Scope) {
+		scope.AddBreadcrumb(breadcrumb, MaxContextBreadcrumbs)
+	})
+}
+
+// withScope stores a copy of the scope of the given context, modified by fn, in a new
+// context. The scope of the given context stays unchanged.
+func withScope(ctx context.Context, fn func(scope *sentry.Scope)) context.Context {
+	var client *sentry.Client
+	var scope *sentry.Scope
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		client = hub.Client()
+		scope = hub.Scope().Clone()
+	} else {
+		scope = sentry.NewScope()
+	}
+	fn(scope)
+	return sentry.SetHubOnContext(ctx, sentry.NewHub(client, scope))
+}
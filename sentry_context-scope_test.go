@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("Context scope", func() {
+	var ctx context.Context
+	var recorder *sentrytest.Recorder
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = sentrytest.NewRecorder()
+	})
+	capture := func(ctx context.Context, scope libsentry.EventModifier) *libsentry.Event {
+		recorder.Reset()
+		recorder.CaptureException(stderrors.New("banana"), &libsentry.EventHint{Context: ctx}, scope)
+		Expect(recorder.Events()).To(HaveLen(1))
+		return recorder.Events()[0]
+	}
+	It("merges tags, user, level and fingerprint", func() {
+		ctx = sentry.WithTag(ctx, "tenant", "t-1")
+		ctx = sentry.WithTags(ctx, map[string]string{"region": "eu"})
+		ctx = sentry.WithUser(ctx, libsentry.User{ID: "u-1"})
+		ctx = sentry.WithLevel(ctx, libsentry.LevelWarning)
+		ctx = sentry.WithFingerprint(ctx, "payment", "timeout")
+		event := capture(ctx, nil)
+		Expect(event.Tags).To(HaveKeyWithValue("tenant", "t-1"))
+		Expect(event.Tags).To(HaveKeyWithValue("region", "eu"))
+		Expect(event.User.ID).To(Equal("u-1"))
+		Expect(event.Level).To(Equal(libsentry.LevelWarning))
+		Expect(event.Fingerprint).To(Equal([]string{"payment", "timeout"}))
+	})
+	It("merges breadcrumbs", func() {
+		ctx = sentry.WithBreadcrumb(ctx, &libsentry.Breadcrumb{Message: "load user"})
+		ctx = sentry.WithBreadcrumb(ctx, &libsentry.Breadcrumb{Message: "charge card"})
+		event := capture(ctx, nil)
+		Expect(event.Breadcrumbs).To(HaveLen(2))
+		Expect(event.Breadcrumbs[1].Message).To(Equal("charge card"))
+	})
+	It("keeps the parent context unchanged", func() {
+		parent := sentry.WithTag(ctx, "tenant", "t-1")
+		_ = sentry.WithTag(parent, "tenant", "t-2")
+		Expect(capture(parent, nil).Tags).To(HaveKeyWithValue("tenant", "t-1"))
+	})
+	It("lets the explicit scope override the context scope", func() {
+		ctx = sentry.WithTag(ctx, "tenant", "t-1")
+		scope := libsentry.NewScope()
+		scope.SetTag("tenant", "t-2")
+		Expect(capture(ctx, scope).Tags).To(HaveKeyWithValue("tenant", "t-2"))
+	})
+	It("merges the scope into messages", func() {
+		ctx = sentry.WithTag(ctx, "tenant", "t-1")
+		recorder.CaptureMessage("hello", &libsentry.EventHint{Context: ctx}, nil)
+		Expect(recorder).To(sentrytest.HaveCapturedEvent(sentrytest.HaveTag("tenant", "t-1")))
+	})
+})
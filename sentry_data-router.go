@@ -0,0 +1,130 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// DataContext is the event context containing the data of context, error and hint that
+// is routed to DataRouteContext. Values keep their original types.
+const DataContext = "bborbe.errors"
+
+// DataRoute defines where a data entry of context, error or hint is attached to the event.
+type DataRoute string
+
+const (
+	// DataRouteTag attaches the value as indexed and searchable tag. Nested maps, structs
+	// and TagProvider are flattened with dotted keys, all other values are formatted.
+	DataRouteTag DataRoute = "tag"
+	// DataRouteContext attaches the value with its original type to context DataContext.
+	DataRouteContext DataRoute = "context"
+)
+
+// DataRouter decides for each data entry of context, error and hint where it is attached.
+type DataRouter func(key string, value any) DataRoute
+
+// DataRouteAllTags routes all data to tags. This is the default.
+func DataRouteAllTags() DataRouter {
+	return func(key string, value any) DataRoute {
+		return DataRouteTag
+	}
+}
+
+// DataRouteTagKeys routes the data of the given keys to tags and everything else to
+// context DataContext.
+//
+//	options.DataRouter = sentry.DataRouteTagKeys("service", "tenant")
+func DataRouteTagKeys(keys ...string) DataRouter {
+	return func(key string, value any) DataRoute {
+		if slices.Contains(keys, key) {
+			return DataRouteTag
+		}
+		return DataRouteContext
+	}
+}
+
+// addData attaches the given value to the event as routed by the router.
+func addData(event *sentry.Event, router DataRouter, key string, value any) {
+	if router(key, value) == DataRouteContext {
+		if event.Contexts == nil {
+			event.Contexts = make(map[string]sentry.Context)
+		}
+		if event.Contexts[DataContext] == nil {
+			event.Contexts[DataContext] = make(sentry.Context)
+		}
+		event.Contexts[DataContext][key] = value
+		return
+	}
+	addTag(event.Tags, key, value)
+}
+
+// maxTagDepth is the depth up to which nested values are flattened into tags. Deeper
+// values are formatted.
+const maxTagDepth = 10
+
+// addTag adds the value as tag. TagProvider, maps and structs are flattened with dotted
+// keys, fmt.Stringer and error values are formatted by their String and Error methods.
+// Values nested deeper than maxTagDepth or referencing themselves are formatted.
+func addTag(tags map[string]string, key string, value any) {
+	addTagWithDepth(tags, key, value, 0, make(map[uintptr]bool))
+}
+
+// addTagWithDepth adds the value as tag. Visited contains the pointers of the values
+// currently flattened, which detects cycles.
+func addTagWithDepth(
+	tags map[string]string,
+	key string,
+	value any,
+	depth int,
+	visited map[uintptr]bool,
+) {
+	switch v := value.(type) {
+	case map[string]string:
+		for k, vv := range v {
+			tags[key+"."+k] = vv
+		}
+		return
+	case TagProvider:
+	case fmt.Stringer, error:
+		tags[key] = formatTagValue(value)
+		return
+	}
+	pointer, isReference := referenceOf(value)
+	if depth >= maxTagDepth || (isReference && visited[pointer]) {
+		tags[key] = formatTagValue(value)
+		return
+	}
+	data, ok := toDataMap(value)
+	if !ok {
+		tags[key] = formatTagValue(value)
+		return
+	}
+	if isReference {
+		visited[pointer] = true
+		defer delete(visited, pointer)
+	}
+	for k, vv := range data {
+		addTagWithDepth(tags, key+"."+k, vv, depth+1, visited)
+	}
+}
+
+// referenceOf returns the address of pointers and maps.
+func referenceOf(value any) (uintptr, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map:
+		if rv.IsNil() {
+			return 0, false
+		}
+		return rv.Pointer(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("DataRouter", func() {
+	var ctx context.Context
+	var router sentry.DataRouter
+	var event *libsentry.Event
+	BeforeEach(func() {
+		router = nil
+		ctx = context.Background()
+		ctx = errors.AddToContext(ctx, "service", "my-app")
+		ctx = errors.AddToContext(ctx, "count", 42)
+		ctx = errors.AddToContext(ctx, "ids", []string{"a", "b"})
+	})
+	JustBeforeEach(func() {
+		recorder := sentrytest.NewRecorderWithOptions(func(options *sentry.Options) {
+			if router != nil {
+				options.DataRouter = router
+			}
+		})
+		recorder.CaptureException(
+			stderrors.New("banana"),
+			&libsentry.EventHint{
+				Context: ctx,
+				Data: map[string]any{
+					"request": map[string]any{
+						"method": "GET",
+						"retry":  map[string]any{"count": 2},
+					},
+				},
+			},
+			nil,
+		)
+		Expect(recorder.Events()).To(HaveLen(1))
+		event = recorder.Events()[0]
+	})
+	Context("default", func() {
+		It("adds all data as tags", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("service", "my-app"))
+			Expect(event.Tags).To(HaveKeyWithValue("count", "42"))
+			Expect(event.Tags).To(HaveKeyWithValue("ids", "[a b]"))
+			Expect(event.Contexts).NotTo(HaveKey(sentry.DataContext))
+		})
+		It("flattens nested maps with dotted keys", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("request.method", "GET"))
+			Expect(event.Tags).To(HaveKeyWithValue("request.retry.count", "2"))
+			Expect(event.Tags).NotTo(HaveKey("request"))
+		})
+	})
+	Context("tag keys", func() {
+		BeforeEach(func() {
+			router = sentry.DataRouteTagKeys("service")
+		})
+		It("keeps selected keys as tags", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("service", "my-app"))
+			Expect(event.Contexts[sentry.DataContext]).NotTo(HaveKey("service"))
+		})
+		It("moves other keys with original types to context", func() {
+			Expect(event.Tags).NotTo(HaveKey("count"))
+			Expect(event.Tags).NotTo(HaveKey("request.method"))
+			Expect(event.Contexts[sentry.DataContext]).To(HaveKeyWithValue("count", 42))
+			Expect(event.Contexts[sentry.DataContext]).To(
+				HaveKeyWithValue("ids", []string{"a", "b"}),
+			)
+			Expect(event.Contexts[sentry.DataContext]).To(HaveKeyWithValue("request", map[string]any{
+				"method": "GET",
+				"retry":  map[string]any{"count": 2},
+			}))
+		})
+	})
+})
@@ -0,0 +1,326 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	stdtime "time"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// DeduplicationOptions configures the client created by NewDeduplicatingClient.
+type DeduplicationOptions struct {
+	// Limit is the number of events per fingerprint sent within one Window.
+	Limit int
+	// Window is the duration after which the limit of a fingerprint resets.
+	Window stdtime.Duration
+	// Frames is the number of top stack frames included in the fingerprint.
+	Frames int
+	// Tags are the keys of context, error and hint data included in the fingerprint.
+	Tags []string
+	// SummaryInterval is the interval in which a background goroutine emits the summaries
+	// of ended windows. Defaults to Window if zero; negative disables the goroutine.
+	SummaryInterval stdtime.Duration
+	// Now returns the current time. Defaults to time.Now and can be replaced in tests.
+	Now func() stdtime.Time
+}
+
+// NewDeduplicatingClient wraps the given client and limits the number of identical events.
+// Events are identified by a fingerprint of error type, message, top stack frames and the
+// configured tags. Per fingerprint only Limit events are sent within Window; further events
+// are suppressed. Events not sent by the wrapped client, e.g. excluded ones, are not counted.
+// After the window ends a summary event with the suppressed count in
+// context "deduplication" is sent. Summaries are emitted every SummaryInterval and on the
+// next capture or Flush after the window ended; Close stops the background goroutine and
+// emits all pending summaries.
+func NewDeduplicatingClient(
+	sentryClient Client,
+	optionFns ...func(options *DeduplicationOptions),
+) Client {
+	options := DeduplicationOptions{
+		Limit:  10,
+		Window: stdtime.Minute,
+		Frames: 5,
+		Now:    stdtime.Now,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	if options.SummaryInterval == 0 {
+		options.SummaryInterval = options.Window
+	}
+	client := &deduplicatingClient{
+		Client:  sentryClient,
+		options: options,
+		entries: make(map[string]*deduplicationEntry),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if options.SummaryInterval > 0 {
+		go client.run()
+	} else {
+		close(client.stopped)
+	}
+	return client
+}
+
+type deduplicatingClient struct {
+	Client
+	options DeduplicationOptions
+
+	mux     sync.Mutex
+	entries map[string]*deduplicationEntry
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+type deduplicationEntry struct {
+	fingerprint string
+	message     string
+	windowStart stdtime.Time
+	count       int
+	suppressed  int
+}
+
+func (d *deduplicatingClient) CaptureMessage(
+	message string,
+	hint *sentry.EventHint,
+	scope sentry.EventModifier,
+) *sentry.EventID {
+	fingerprint := d.fingerprint("message", message, nil, hint)
+	if !d.allow(fingerprint, message) {
+		return nil
+	}
+	eventID := d.Client.CaptureMessage(message, hint, scope)
+	if eventID == nil {
+		d.release(fingerprint)
+	}
+	return eventID
+}
+
+func (d *deduplicatingClient) CaptureException(
+	err error,
+	hint *sentry.EventHint,
+	scope sentry.EventModifier,
+) *sentry.EventID {
+	message := "<nil>"
+	errorType := "<nil>"
+	if err != nil {
+		message = err.Error()
+		errorType = reflect.TypeOf(errors.Cause(err)).String()
+	}
+	fingerprint := d.fingerprint(errorType, message, err, hint)
+	if !d.allow(fingerprint, message) {
+		return nil
+	}
+	eventID := d.Client.CaptureException(err, hint, scope)
+	if eventID == nil {
+		d.release(fingerprint)
+	}
+	return eventID
+}
+
+func (d *deduplicatingClient) Flush(timeout stdtime.Duration) bool {
+	d.emitSummaries(d.expired(d.options.Now()))
+	return d.Client.Flush(timeout)
+}
+
+func (d *deduplicatingClient) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.done)
+	})
+	<-d.stopped
+	d.mux.Lock()
+	entries := make([]deduplicationEntry, 0, len(d.entries))
+	for fingerprint, entry := range d.entries {
+		if entry.suppressed > 0 {
+			entries = append(entries, *entry)
+		}
+		delete(d.entries, fingerprint)
+	}
+	d.mux.Unlock()
+	d.emitSummaries(entries)
+	return d.Client.Close()
+}
+
+// run emits the summaries of ended windows every SummaryInterval until Close.
+func (d *deduplicatingClient) run() {
+	defer close(d.stopped)
+	ticker := stdtime.NewTicker(d.options.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.emitSummaries(d.expired(d.options.Now()))
+		}
+	}
+}
+
+// allow counts the event and returns true if it is within the limit.
+func (d *deduplicatingClient) allow(fingerprint string, message string) bool {
+	now := d.options.Now()
+	d.emitSummaries(d.expired(now))
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	entry, ok := d.entries[fingerprint]
+	if !ok {
+		entry = &deduplicationEntry{
+			fingerprint: fingerprint,
+			message:     message,
+			windowStart: now,
+		}
+		d.entries[fingerprint] = entry
+	}
+	entry.count++
+	if entry.count <= d.options.Limit {
+		return true
+	}
+	entry.suppressed++
+	glog.V(3).Infof("suppress duplicate sentry event %s (%d)", fingerprint, entry.suppressed)
+	return false
+}
+
+// release uncounts an allowed event the wrapped client did not send, e.g. because it was
+// excluded, so only sent events count against the limit.
+func (d *deduplicatingClient) release(fingerprint string) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	entry, ok := d.entries[fingerprint]
+	if !ok || entry.count == 0 {
+		return
+	}
+	entry.count--
+	if entry.count == 0 && entry.suppressed == 0 {
+		delete(d.entries, fingerprint)
+	}
+}
+
+// expired removes all entries whose window ended and returns the ones with suppressed events.
+func (d *deduplicatingClient) expired(now stdtime.Time) []deduplicationEntry {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	var result []deduplicationEntry
+	for fingerprint, entry := range d.entries {
+		if now.Sub(entry.windowStart) < d.options.Window {
+			continue
+		}
+		if entry.suppressed > 0 {
+			result = append(result, *entry)
+		}
+		delete(d.entries, fingerprint)
+	}
+	return result
+}
+
+func (d *deduplicatingClient) emitSummaries(entries []deduplicationEntry) {
+	for _, entry := range entries {
+		scope := sentry.NewScope()
+		scope.SetLevel(sentry.LevelWarning)
+		scope.SetFingerprint([]string{"deduplication-summary", entry.fingerprint})
+		scope.SetContext("deduplication", sentry.Context{
+			"suppressed_count": entry.suppressed,
+			"fingerprint":      entry.fingerprint,
+		})
+		d.Client.CaptureMessage(
+			fmt.Sprintf("suppressed %d duplicate events: %s", entry.suppressed, entry.message),
+			&sentry.EventHint{},
+			scope,
+		)
+	}
+}
+
+// fingerprint computes a stable hash of error type, message, top frames and selected tags.
+func (d *deduplicatingClient) fingerprint(
+	errorType string,
+	message string,
+	err error,
+	hint *sentry.EventHint,
+) string {
+	parts := []string{errorType, message}
+	parts = append(parts, d.topFrames(err)...)
+	parts = append(parts, d.selectedTags(err, hint)...)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// topFrames returns the top frames of the first stacktrace found in the error chain or
+// of the caller if the error carries no stacktrace.
+func (d *deduplicatingClient) topFrames(err error) []string {
+	if d.options.Frames <= 0 {
+		return nil
+	}
+	for e := err; e != nil; e = stderrors.Unwrap(e) {
+		stacktrace := sentry.ExtractStacktrace(e)
+		if stacktrace == nil || len(stacktrace.Frames) == 0 {
+			continue
+		}
+		frames := stacktrace.Frames
+		frames = frames[max(0, len(frames)-d.options.Frames):]
+		result := make([]string, 0, len(frames))
+		for _, frame := range frames {
+			result = append(result, fmt.Sprintf("%s.%s:%d", frame.Module, frame.Function, frame.Lineno))
+		}
+		return result
+	}
+	pcs := make([]uintptr, d.options.Frames)
+	// skip runtime.Callers, topFrames, fingerprint and the Capture method
+	n := runtime.Callers(4, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var result []string
+	for {
+		frame, more := frames.Next()
+		result = append(result, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+func (d *deduplicatingClient) selectedTags(err error, hint *sentry.EventHint) []string {
+	if len(d.options.Tags) == 0 {
+		return nil
+	}
+	data := make(map[string]any)
+	if hint != nil && hint.Context != nil {
+		for k, v := range errors.DataFromContext(hint.Context) {
+			data[k] = v
+		}
+	}
+	if err != nil {
+		for k, v := range errors.DataFromError(err) {
+			data[k] = v
+		}
+	}
+	if hint != nil {
+		for k, v := range hintData(hint) {
+			data[k] = v
+		}
+	}
+	result := make([]string, 0, len(d.options.Tags))
+	for _, key := range d.options.Tags {
+		if value, ok := data[key]; ok {
+			result = append(result, fmt.Sprintf("%s=%v", key, value))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	sentrymocks "github.com/bborbe/sentry/mocks"
+)
+
+var _ = Describe("DeduplicatingClient", func() {
+	var ctx context.Context
+	var now time.Time
+	var sentryClient *sentrymocks.SentryClient
+	var deduplicatingClient sentry.Client
+	var capture func(err error)
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		sentryClient = &sentrymocks.SentryClient{}
+		id := libsentry.EventID("id")
+		sentryClient.CaptureExceptionReturns(&id)
+		sentryClient.CaptureMessageReturns(&id)
+		deduplicatingClient = sentry.NewDeduplicatingClient(
+			sentryClient,
+			func(options *sentry.DeduplicationOptions) {
+				options.Limit = 2
+				options.Window = time.Minute
+				options.Tags = []string{"db"}
+				options.Now = func() time.Time { return now }
+			},
+		)
+		capture = func(err error) {
+			deduplicatingClient.CaptureException(
+				err,
+				&libsentry.EventHint{Context: errors.AddToContext(ctx, "db", "users")},
+				nil,
+			)
+		}
+	})
+	It("forwards events up to the limit", func() {
+		for i := 0; i < 5; i++ {
+			capture(stderrors.New("connection refused"))
+		}
+		Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(2))
+		Expect(sentryClient.CaptureMessageCallCount()).To(Equal(0))
+	})
+	It("counts different errors separately", func() {
+		for i := 0; i < 3; i++ {
+			capture(stderrors.New("connection refused"))
+			capture(stderrors.New("timeout"))
+		}
+		Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(4))
+	})
+	It("counts different tags separately", func() {
+		for i := 0; i < 3; i++ {
+			capture(stderrors.New("connection refused"))
+			deduplicatingClient.CaptureException(
+				stderrors.New("connection refused"),
+				&libsentry.EventHint{Context: errors.AddToContext(ctx, "db", "orders")},
+				nil,
+			)
+		}
+		Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(4))
+	})
+	It("emits summary after window", func() {
+		for i := 0; i < 5; i++ {
+			capture(stderrors.New("connection refused"))
+		}
+		now = now.Add(2 * time.Minute)
+		capture(stderrors.New("connection refused"))
+
+		Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(3))
+		Expect(sentryClient.CaptureMessageCallCount()).To(Equal(1))
+		message, _, scope := sentryClient.CaptureMessageArgsForCall(0)
+		Expect(message).To(Equal("suppressed 3 duplicate events: connection refused"))
+		event := scope.ApplyToEvent(&libsentry.Event{}, nil, nil)
+		Expect(event.Contexts).To(HaveKey("deduplication"))
+		Expect(event.Contexts["deduplication"]).To(HaveKeyWithValue("suppressed_count", 3))
+	})
+	It("emits no summary without suppressed events", func() {
+		capture(stderrors.New("connection refused"))
+		now = now.Add(2 * time.Minute)
+		Expect(deduplicatingClient.Flush(time.Second)).To(BeFalse())
+		Expect(sentryClient.CaptureMessageCallCount()).To(Equal(0))
+	})
+	It("emits pending summaries on close", func() {
+		for i := 0; i < 3; i++ {
+			capture(stderrors.New("connection refused"))
+		}
+		Expect(deduplicatingClient.Close()).To(BeNil())
+		Expect(sentryClient.CaptureMessageCallCount()).To(Equal(1))
+		Expect(sentryClient.CloseCallCount()).To(Equal(1))
+	})
+	It("emits summaries of ended windows without further events", func() {
+		var nowMux sync.Mutex
+		clock := now
+		deduplicatingClient = sentry.NewDeduplicatingClient(
+			sentryClient,
+			func(options *sentry.DeduplicationOptions) {
+				options.Limit = 1
+				options.Window = time.Minute
+				options.SummaryInterval = 10 * time.Millisecond
+				options.Now = func() time.Time {
+					nowMux.Lock()
+					defer nowMux.Unlock()
+					return clock
+				}
+			},
+		)
+		defer deduplicatingClient.Close()
+		for i := 0; i < 3; i++ {
+			capture(stderrors.New("connection refused"))
+		}
+		Consistently(sentryClient.CaptureMessageCallCount, 50*time.Millisecond).Should(Equal(0))
+
+		nowMux.Lock()
+		clock = clock.Add(2 * time.Minute)
+		nowMux.Unlock()
+		Eventually(sentryClient.CaptureMessageCallCount).Should(Equal(1))
+		message, _, _ := sentryClient.CaptureMessageArgsForCall(0)
+		Expect(message).To(Equal("suppressed 2 duplicate events: connection refused"))
+	})
+	It("counts no events excluded by the wrapped client", func() {
+		sentryClient.CaptureExceptionStub = func(
+			err error,
+			hint *libsentry.EventHint,
+			scope libsentry.EventModifier,
+		) *libsentry.EventID {
+			if stderrors.Is(err, context.Canceled) {
+				return nil
+			}
+			id := libsentry.EventID("id")
+			return &id
+		}
+		for i := 0; i < 5; i++ {
+			capture(context.Canceled)
+		}
+		Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(5))
+
+		now = now.Add(2 * time.Minute)
+		deduplicatingClient.Flush(time.Second)
+		Expect(deduplicatingClient.Close()).To(BeNil())
+		Expect(sentryClient.CaptureMessageCallCount()).To(Equal(0))
+	})
+	It("deduplicates messages", func() {
+		for i := 0; i < 3; i++ {
+			deduplicatingClient.CaptureMessage("hello", nil, nil)
+		}
+		Expect(sentryClient.CaptureMessageCallCount()).To(Equal(2))
+	})
+	It("is concurrency safe", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				capture(stderrors.New("connection refused"))
+			}()
+		}
+		wg.Wait()
+		Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(2))
+	})
+})
@@ -0,0 +1,218 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+)
+
+// newExceptionChainModifier creates an EventModifier that enriches each exception of the
+// chain built by the SDK, including the branches of errors.Join. The data attached to a
+// link with github.com/bborbe/errors is added to its mechanism data.
+//
+// If stacktraces is true each exception gets the stacktrace recorded by the error itself,
+// e.g. by errors.Wrap of github.com/bborbe/errors or any error with a pkg/errors
+// StackTrace() method. Exceptions without own stacktrace get the stacktrace of the nearest
+// wrapped error, which replaces the stacktrace of the CaptureException call site added by
+// the SDK. Frames of the error libraries are removed from the top of the stacktraces.
+func newExceptionChainModifier(stacktraces bool) EventModifier {
+	return EventModifierFunc(
+		func(event *sentry.Event, hint *sentry.EventHint, client *sentry.Client) *sentry.Event {
+			if hint == nil || hint.OriginalException == nil || len(event.Exception) == 0 {
+				return event
+			}
+			maxErrorDepth := -1
+			if client != nil {
+				maxErrorDepth = client.Options().MaxErrorDepth
+			}
+			chain := newErrorChain(hint.OriginalException, maxErrorDepth)
+			if len(chain.errors) != len(event.Exception) {
+				return event
+			}
+			for i := range event.Exception {
+				exception := &event.Exception[i]
+				id := 0
+				if exception.Mechanism != nil {
+					id = exception.Mechanism.ExceptionID
+				}
+				if id < 0 || id >= len(chain.errors) || chain.errors[id].Error() != exception.Value {
+					continue
+				}
+				addMechanismData(exception, chain.errors[id])
+				if !stacktraces {
+					continue
+				}
+				if stacktrace := chain.stacktrace(id); stacktrace != nil {
+					exception.Stacktrace = stacktrace
+				}
+			}
+			return event
+		},
+	)
+}
+
+// addMechanismData adds the data of the error returned by errors.DataFromError.
+func addMechanismData(exception *sentry.Exception, err error) {
+	data := errors.DataFromError(err)
+	if len(data) == 0 {
+		return
+	}
+	if exception.Mechanism == nil {
+		exception.Mechanism = &sentry.Mechanism{
+			Type: sentry.MechanismTypeGeneric,
+		}
+	}
+	if exception.Mechanism.Data == nil {
+		exception.Mechanism.Data = make(map[string]any, len(data))
+	}
+	maps.Copy(exception.Mechanism.Data, data)
+}
+
+// errorChain contains the errors in the order sentry-go assigns exception ids to them.
+type errorChain struct {
+	errors  []error
+	parents []int
+}
+
+func newErrorChain(err error, maxErrorDepth int) *errorChain {
+	chain := &errorChain{}
+	chain.walk(err, -1, maxErrorDepth, 0, &errorVisited{
+		pointers: make(map[uintptr]bool),
+		messages: make(map[string]bool),
+	})
+	return chain
+}
+
+// walk mirrors the depth first traversal of sentry-go, including its skipping of errors
+// already visited.
+func (c *errorChain) walk(
+	err error,
+	parent int,
+	maxErrorDepth int,
+	depth int,
+	visited *errorVisited,
+) {
+	if err == nil || visited.seen(err) {
+		return
+	}
+	id := len(c.errors)
+	c.errors = append(c.errors, err)
+	c.parents = append(c.parents, parent)
+	if maxErrorDepth >= 0 && depth >= maxErrorDepth {
+		return
+	}
+	switch v := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, child := range v.Unwrap() {
+			c.walk(child, id, maxErrorDepth, depth+1, visited)
+		}
+	case interface{ Unwrap() error }:
+		c.walk(v.Unwrap(), id, maxErrorDepth, depth+1, visited)
+	case interface{ Cause() error }:
+		c.walk(v.Cause(), id, maxErrorDepth, depth+1, visited)
+	}
+}
+
+// stacktrace returns the stacktrace of the error with the given id or of its nearest
+// descendant.
+func (c *errorChain) stacktrace(id int) *sentry.Stacktrace {
+	for i := id; i < len(c.errors); i++ {
+		if i != id && !c.isDescendant(i, id) {
+			break
+		}
+		if stacktrace := sentry.ExtractStacktrace(c.errors[i]); stacktrace != nil {
+			return trimErrorFrames(stacktrace)
+		}
+	}
+	return nil
+}
+
+// errorModules are the modules whose frames are removed from the top of error stacktraces.
+var errorModules = []string{
+	"github.com/bborbe/errors",
+	"github.com/pkg/errors",
+}
+
+// trimErrorFrames removes the frames of the error libraries from the top of the stacktrace,
+// so it ends in the function creating or wrapping the error.
+func trimErrorFrames(stacktrace *sentry.Stacktrace) *sentry.Stacktrace {
+	frames := stacktrace.Frames
+	for len(frames) > 1 && slices.Contains(errorModules, frames[len(frames)-1].Module) {
+		frames = frames[:len(frames)-1]
+	}
+	return &sentry.Stacktrace{
+		Frames:        frames,
+		FramesOmitted: stacktrace.FramesOmitted,
+	}
+}
+
+func (c *errorChain) isDescendant(id int, ancestor int) bool {
+	for parent := c.parents[id]; parent >= 0; parent = c.parents[parent] {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// errorVisited mirrors how sentry-go detects errors visited already: pointers by address,
+// all other errors by type and message.
+type errorVisited struct {
+	pointers map[uintptr]bool
+	messages map[string]bool
+}
+
+func (v *errorVisited) seen(err error) bool {
+	value := reflect.ValueOf(err)
+	if value.Kind() == reflect.Pointer && !value.IsNil() {
+		if v.pointers[value.Pointer()] {
+			return true
+		}
+		v.pointers[value.Pointer()] = true
+		return false
+	}
+	key := value.String() + err.Error()
+	if v.messages[key] {
+		return true
+	}
+	v.messages[key] = true
+	return false
+}
+
+// newInAppProcessor creates an event processor that marks the frames of all stacktraces as
+// in app if their module starts with any of the given prefixes and as not in app otherwise.
+func newInAppProcessor(modulePrefixes []string) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		for i := range event.Exception {
+			markInApp(event.Exception[i].Stacktrace, modulePrefixes)
+		}
+		for i := range event.Threads {
+			markInApp(event.Threads[i].Stacktrace, modulePrefixes)
+		}
+		return event
+	}
+}
+
+func markInApp(stacktrace *sentry.Stacktrace, modulePrefixes []string) {
+	if stacktrace == nil {
+		return
+	}
+	for i := range stacktrace.Frames {
+		frame := &stacktrace.Frames[i]
+		frame.InApp = false
+		for _, prefix := range modulePrefixes {
+			if strings.HasPrefix(frame.Module, prefix) {
+				frame.InApp = true
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+func stacktraceNewError(ctx context.Context) error {
+	return errors.New(ctx, "root")
+}
+
+func stacktraceWrapError(ctx context.Context) error {
+	return errors.Wrap(ctx, stacktraceNewError(ctx), "outer")
+}
+
+func topFunction(exception libsentry.Exception) string {
+	Expect(exception.Stacktrace).NotTo(BeNil())
+	frames := exception.Stacktrace.Frames
+	Expect(frames).NotTo(BeEmpty())
+	return frames[len(frames)-1].Function
+}
+
+var _ = Describe("Error stacktraces", func() {
+	var ctx context.Context
+	var optionFn func(options *sentry.Options)
+	var event *libsentry.Event
+	BeforeEach(func() {
+		ctx = errors.AddToContext(context.Background(), "service", "my-app")
+		optionFn = func(options *sentry.Options) {}
+	})
+	JustBeforeEach(func() {
+		recorder := sentrytest.NewRecorderWithOptions(optionFn)
+		recorder.CaptureException(stacktraceWrapError(ctx), &libsentry.EventHint{Context: ctx}, nil)
+		Expect(recorder.Events()).To(HaveLen(1))
+		event = recorder.Events()[0]
+	})
+	It("uses the stacktrace of the wrap for the outermost exception", func() {
+		Expect(topFunction(event.Exception[len(event.Exception)-1])).To(Equal("stacktraceWrapError"))
+	})
+	It("uses the stacktrace of the creation for the root exception", func() {
+		Expect(topFunction(event.Exception[0])).To(Equal("stacktraceNewError"))
+	})
+	It("attaches a stacktrace to every exception", func() {
+		for _, exception := range event.Exception {
+			Expect(exception.Stacktrace).NotTo(BeNil())
+		}
+	})
+	Context("disabled", func() {
+		BeforeEach(func() {
+			optionFn = func(options *sentry.Options) {
+				options.ErrorStacktraces = false
+			}
+		})
+		It("keeps the stacktrace of the call site", func() {
+			Expect(topFunction(event.Exception[len(event.Exception)-1])).NotTo(
+				Equal("stacktraceWrapError"),
+			)
+		})
+	})
+	Context("in app modules", func() {
+		BeforeEach(func() {
+			optionFn = func(options *sentry.Options) {
+				options.InAppModules = []string{"github.com/bborbe/sentry_test"}
+			}
+		})
+		It("marks frames by module prefix", func() {
+			for _, frame := range event.Exception[0].Stacktrace.Frames {
+				Expect(frame.InApp).To(Equal(frame.Module == "github.com/bborbe/sentry_test"), frame.Module)
+			}
+		})
+	})
+})
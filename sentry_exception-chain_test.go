@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+)
+
+var _ = Describe("Exception chain", func() {
+	var ctx context.Context
+	var maxErrorDepth int
+	var err error
+	var event *libsentry.Event
+	BeforeEach(func() {
+		ctx = context.Background()
+		maxErrorDepth = 0
+		err = errors.Join(
+			errors.AddDataToError(stderrors.New("db failed"), map[string]any{"db": "users"}),
+			errors.AddDataToError(stderrors.New("cache failed"), map[string]any{"cache": "redis"}),
+		)
+	})
+	JustBeforeEach(func() {
+		var events []*libsentry.Event
+		client, clientErr := sentry.NewClient(ctx, libsentry.ClientOptions{
+			MaxErrorDepth: maxErrorDepth,
+			BeforeSend: func(event *libsentry.Event, hint *libsentry.EventHint) *libsentry.Event {
+				events = append(events, event)
+				return nil
+			},
+		})
+		Expect(clientErr).To(BeNil())
+		client.CaptureException(err, &libsentry.EventHint{Context: ctx}, nil)
+		Expect(events).To(HaveLen(1))
+		event = events[0]
+	})
+	findException := func(value string) libsentry.Exception {
+		for _, exception := range event.Exception {
+			if exception.Value == value {
+				return exception
+			}
+		}
+		Fail("exception " + value + " not found")
+		return libsentry.Exception{}
+	}
+	It("reports joined errors as exception group", func() {
+		outermost := event.Exception[len(event.Exception)-1]
+		Expect(outermost.Mechanism).NotTo(BeNil())
+		Expect(outermost.Mechanism.IsExceptionGroup).To(BeTrue())
+		Expect(outermost.Mechanism.ExceptionID).To(Equal(0))
+	})
+	It("links joined errors to their parent", func() {
+		for _, value := range []string{"db failed", "cache failed"} {
+			mechanism := findException(value).Mechanism
+			Expect(mechanism).NotTo(BeNil())
+			Expect(mechanism.ParentID).NotTo(BeNil())
+		}
+	})
+	It("adds the data of each link to its mechanism", func() {
+		var data []map[string]any
+		for _, exception := range event.Exception {
+			if exception.Type == "*errors.dataError" {
+				data = append(data, exception.Mechanism.Data)
+			}
+		}
+		Expect(data).To(ConsistOf(
+			map[string]any{"db": "users"},
+			map[string]any{"cache": "redis"},
+		))
+	})
+	Context("single error with data", func() {
+		BeforeEach(func() {
+			err = errors.AddDataToError(stderrors.New("banana"), map[string]any{"fruit": "yellow"})
+		})
+		It("adds mechanism data", func() {
+			outermost := event.Exception[len(event.Exception)-1]
+			Expect(outermost.Mechanism).NotTo(BeNil())
+			Expect(outermost.Mechanism.Data).To(HaveKeyWithValue("fruit", "yellow"))
+		})
+	})
+	Context("max depth", func() {
+		BeforeEach(func() {
+			maxErrorDepth = 1
+			err = errors.Wrap(ctx, errors.Wrap(ctx, stderrors.New("root"), "middle"), "outer")
+		})
+		It("limits the number of exceptions", func() {
+			Expect(event.Exception).To(HaveLen(2))
+		})
+	})
+})
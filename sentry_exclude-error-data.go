@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"fmt"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+)
+
+// DataValueMatcher decides whether a value attached with errors.AddDataToError or
+// errors.AddToContext matches.
+type DataValueMatcher func(value any) bool
+
+// DataValueEquals matches values whose string representation equals the given value.
+//
+//	sentry.ExcludeErrorWithData("expected", sentry.DataValueEquals("true"))
+func DataValueEquals(expected any) DataValueMatcher {
+	expectedString := fmt.Sprintf("%v", expected)
+	return func(value any) bool {
+		return fmt.Sprintf("%v", value) == expectedString
+	}
+}
+
+// DataValueIn matches values whose string representation equals any of the given values.
+//
+//	sentry.ExcludeErrorWithData("http_status", sentry.DataValueIn("404", "409"))
+func DataValueIn(expected ...any) DataValueMatcher {
+	matchers := make([]DataValueMatcher, 0, len(expected))
+	for _, e := range expected {
+		matchers = append(matchers, DataValueEquals(e))
+	}
+	return func(value any) bool {
+		for _, matcher := range matchers {
+			if matcher(value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ExcludeErrorWithData excludes errors that carry data with the given key and a value
+// matching valueMatcher anywhere in their chain, including errors wrapped with
+// fmt.Errorf("%w") and joined errors.
+//
+//	err = errors.AddDataToError(err, map[string]any{"expected": "true"})
+//	sentry.ExcludeErrorWithData("expected", sentry.DataValueEquals("true"))
+func ExcludeErrorWithData(key string, valueMatcher DataValueMatcher) ExcludeError {
+	return func(err error) bool {
+		return errorHasData(err, key, valueMatcher)
+	}
+}
+
+// ExcludeErrorHintWithData excludes errors that carry matching data like ExcludeErrorWithData
+// or whose hint.Context contains matching data added with errors.AddToContext.
+//
+//	ctx = errors.AddToContext(ctx, "expected", "true")
+//	sentry.ExcludeErrorHintWithData("expected", sentry.DataValueEquals("true"))
+func ExcludeErrorHintWithData(key string, valueMatcher DataValueMatcher) ExcludeErrorHint {
+	return func(err error, hint *sentry.EventHint) bool {
+		if errorHasData(err, key, valueMatcher) {
+			return true
+		}
+		if hint == nil || hint.Context == nil {
+			return false
+		}
+		value, ok := errors.DataFromContext(hint.Context)[key]
+		return ok && valueMatcher(value)
+	}
+}
+
+// errorHasData walks the complete error tree and checks the data of every error.
+func errorHasData(err error, key string, valueMatcher DataValueMatcher) bool {
+	if err == nil {
+		return false
+	}
+	if hasData, ok := err.(errors.HasData); ok {
+		if value, ok := hasData.Data()[key]; ok && valueMatcher(value) {
+			return true
+		}
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, child := range e.Unwrap() {
+			if errorHasData(child, key, valueMatcher) {
+				return true
+			}
+		}
+	case interface{ Unwrap() error }:
+		return errorHasData(e.Unwrap(), key, valueMatcher)
+	case errors.HasCause:
+		return errorHasData(e.Cause(), key, valueMatcher)
+	}
+	return false
+}
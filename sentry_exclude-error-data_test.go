@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("ExcludeErrorData", func() {
+	var ctx context.Context
+	var expectedErr error
+	BeforeEach(func() {
+		ctx = context.Background()
+		expectedErr = errors.AddDataToError(
+			stderrors.New("not found"),
+			map[string]any{"expected": "true", "http_status": 404},
+		)
+	})
+	Context("DataValueEquals", func() {
+		It("compares string representation", func() {
+			Expect(sentry.DataValueEquals("404")(404)).To(BeTrue())
+			Expect(sentry.DataValueEquals(true)("true")).To(BeTrue())
+			Expect(sentry.DataValueEquals("404")(500)).To(BeFalse())
+		})
+	})
+	Context("DataValueIn", func() {
+		It("matches any value", func() {
+			Expect(sentry.DataValueIn("404", "409")(409)).To(BeTrue())
+			Expect(sentry.DataValueIn("404", "409")(500)).To(BeFalse())
+			Expect(sentry.DataValueIn()(500)).To(BeFalse())
+		})
+	})
+	Context("ExcludeErrorWithData", func() {
+		var excludeError sentry.ExcludeError
+		BeforeEach(func() {
+			excludeError = sentry.ExcludeErrorWithData("expected", sentry.DataValueEquals("true"))
+		})
+		It("excludes error with data", func() {
+			Expect(excludeError(expectedErr)).To(BeTrue())
+		})
+		It("excludes error wrapped with bborbe/errors", func() {
+			Expect(excludeError(errors.Wrap(ctx, expectedErr, "wrap"))).To(BeTrue())
+		})
+		It("excludes error wrapped with fmt.Errorf", func() {
+			Expect(excludeError(fmt.Errorf("wrap: %w", expectedErr))).To(BeTrue())
+		})
+		It("excludes joined error", func() {
+			Expect(excludeError(stderrors.Join(stderrors.New("other"), expectedErr))).To(BeTrue())
+		})
+		It("does not exclude error without data", func() {
+			Expect(excludeError(stderrors.New("banana"))).To(BeFalse())
+			Expect(excludeError(nil)).To(BeFalse())
+		})
+		It("does not exclude error with other value", func() {
+			Expect(sentry.ExcludeErrorWithData("http_status", sentry.DataValueEquals("500"))(expectedErr)).
+				To(BeFalse())
+		})
+	})
+	Context("ExcludeErrorHintWithData", func() {
+		var excludeErrorHint sentry.ExcludeErrorHint
+		BeforeEach(func() {
+			excludeErrorHint = sentry.ExcludeErrorHintWithData("expected", sentry.DataValueEquals("true"))
+		})
+		It("excludes error with data", func() {
+			Expect(excludeErrorHint(expectedErr, nil)).To(BeTrue())
+		})
+		It("excludes error with context data", func() {
+			ctx = errors.AddToContext(ctx, "expected", "true")
+			Expect(excludeErrorHint(stderrors.New("banana"), &libsentry.EventHint{Context: ctx})).
+				To(BeTrue())
+		})
+		It("does not exclude without data", func() {
+			Expect(excludeErrorHint(stderrors.New("banana"), &libsentry.EventHint{Context: ctx})).
+				To(BeFalse())
+		})
+	})
+	Context("client", func() {
+		var recorder *sentrytest.Recorder
+		BeforeEach(func() {
+			recorder = sentrytest.NewRecorderWithOptions(func(options *sentry.Options) {
+				options.ExcludeErrorHints = sentry.ExcludeErrorHints{
+					sentry.ExcludeErrorHintWithData("expected", sentry.DataValueEquals("true")),
+				}
+			})
+		})
+		It("skips excluded errors", func() {
+			Expect(recorder.CaptureException(expectedErr, nil, nil)).To(BeNil())
+			Expect(recorder.CaptureException(
+				stderrors.New("banana"),
+				&libsentry.EventHint{Context: errors.AddToContext(ctx, "expected", "true")},
+				nil,
+			)).To(BeNil())
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+		It("captures other errors", func() {
+			Expect(recorder.CaptureException(stderrors.New("banana"), nil, nil)).NotTo(BeNil())
+			Expect(recorder).To(sentrytest.HaveCapturedException("banana"))
+		})
+	})
+})
@@ -4,6 +4,13 @@
 
 package sentry
 
+import (
+	"regexp"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+)
+
 // ExcludeErrors is a collection of ExcludeError functions that can be used to filter
 // out specific errors from being sent to Sentry.
 type ExcludeErrors []ExcludeError
@@ -11,14 +18,122 @@ type ExcludeErrors []ExcludeError
 // IsExcluded checks if the given error matches any of the exclude conditions.
 // It returns true if any ExcludeError function in the collection returns true for the error.
 func (e ExcludeErrors) IsExcluded(err error) bool {
+	_, ok := e.match(err)
+	return ok
+}
+
+// match returns the first ExcludeError returning true for the given error.
+func (e ExcludeErrors) match(err error) (ExcludeError, bool) {
 	for _, ee := range e {
 		if ee(err) {
-			return true
+			return ee, true
 		}
 	}
-	return false
+	return nil, false
 }
 
 // ExcludeError is a function type that determines whether an error should be excluded
 // from Sentry reporting. It returns true if the error should be excluded.
 type ExcludeError func(err error) bool
+
+// ExcludeErrorHints is a collection of ExcludeErrorHint functions.
+type ExcludeErrorHints []ExcludeErrorHint
+
+// IsExcluded checks if the given error and hint match any of the exclude conditions.
+func (e ExcludeErrorHints) IsExcluded(err error, hint *sentry.EventHint) bool {
+	_, ok := e.match(err, hint)
+	return ok
+}
+
+// match returns the first ExcludeErrorHint returning true for the given error and hint.
+func (e ExcludeErrorHints) match(err error, hint *sentry.EventHint) (ExcludeErrorHint, bool) {
+	for _, ee := range e {
+		if ee(err, hint) {
+			return ee, true
+		}
+	}
+	return nil, false
+}
+
+// ExcludeErrorHint is like ExcludeError but also receives the hint of the capture,
+// which gives access to hint.Context and hint.Data. It returns true if the error should
+// be excluded. Register it with NewClientWithOptions.
+type ExcludeErrorHint func(err error, hint *sentry.EventHint) bool
+
+// ExcludeErrorIs excludes errors that match any of the given targets with errors.Is.
+//
+//	sentry.ExcludeErrorIs(context.Canceled, context.DeadlineExceeded)
+func ExcludeErrorIs(targets ...error) ExcludeError {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ExcludeErrorAs excludes errors that contain an error of type T in their chain.
+//
+//	sentry.ExcludeErrorAs[*url.Error]()
+func ExcludeErrorAs[T error]() ExcludeError {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// ExcludeErrorMessageRegexp excludes errors whose message matches any of the given
+// regular expressions.
+//
+//	sentry.ExcludeErrorMessageRegexp(regexp.MustCompile(`connection reset by peer`))
+func ExcludeErrorMessageRegexp(regexps ...*regexp.Regexp) ExcludeError {
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		message := err.Error()
+		for _, re := range regexps {
+			if re.MatchString(message) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ExcludeErrorAnd excludes errors that are excluded by all of the given ExcludeError functions.
+// Without any ExcludeError nothing is excluded.
+func ExcludeErrorAnd(excludeErrors ...ExcludeError) ExcludeError {
+	return func(err error) bool {
+		if len(excludeErrors) == 0 {
+			return false
+		}
+		for _, excludeError := range excludeErrors {
+			if !excludeError(err) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ExcludeErrorOr excludes errors that are excluded by any of the given ExcludeError functions.
+func ExcludeErrorOr(excludeErrors ...ExcludeError) ExcludeError {
+	return func(err error) bool {
+		return ExcludeErrors(excludeErrors).IsExcluded(err)
+	}
+}
+
+// ExcludeErrorNot excludes errors that are not excluded by the given ExcludeError function.
+//
+//	sentry.ExcludeErrorAnd(
+//	    sentry.ExcludeErrorIs(context.Canceled),
+//	    sentry.ExcludeErrorNot(sentry.ExcludeErrorAs[*CriticalError]()),
+//	)
+func ExcludeErrorNot(excludeError ExcludeError) ExcludeError {
+	return func(err error) bool {
+		return !excludeError(err)
+	}
+}
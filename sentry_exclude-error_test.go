@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"net/url"
+	"regexp"
+
+	"github.com/bborbe/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+)
+
+var _ = Describe("ExcludeError", func() {
+	var ctx context.Context
+	var yes, no sentry.ExcludeError
+	BeforeEach(func() {
+		ctx = context.Background()
+		yes = func(err error) bool { return true }
+		no = func(err error) bool { return false }
+	})
+	DescribeTable("ExcludeErrors.IsExcluded",
+		func(excludeErrors sentry.ExcludeErrors, expected bool) {
+			Expect(excludeErrors.IsExcluded(stderrors.New("banana"))).To(Equal(expected))
+		},
+		Entry("empty", sentry.ExcludeErrors{}, false),
+		Entry("no match", sentry.ExcludeErrors{func(err error) bool { return false }}, false),
+		Entry("match", sentry.ExcludeErrors{func(err error) bool { return true }}, true),
+	)
+	Context("ExcludeErrorIs", func() {
+		It("excludes wrapped target", func() {
+			excludeError := sentry.ExcludeErrorIs(context.Canceled, context.DeadlineExceeded)
+			Expect(excludeError(errors.Wrap(ctx, context.DeadlineExceeded, "wrap"))).To(BeTrue())
+			Expect(excludeError(context.Canceled)).To(BeTrue())
+			Expect(excludeError(stderrors.New("banana"))).To(BeFalse())
+		})
+	})
+	Context("ExcludeErrorAs", func() {
+		It("excludes error of type", func() {
+			excludeError := sentry.ExcludeErrorAs[*url.Error]()
+			urlErr := &url.Error{Op: "Get", URL: "http://example.com", Err: stderrors.New("eof")}
+			Expect(excludeError(errors.Wrap(ctx, urlErr, "wrap"))).To(BeTrue())
+			Expect(excludeError(stderrors.New("banana"))).To(BeFalse())
+		})
+	})
+	Context("ExcludeErrorMessageRegexp", func() {
+		It("excludes matching message", func() {
+			excludeError := sentry.ExcludeErrorMessageRegexp(
+				regexp.MustCompile(`connection (reset|refused)`),
+			)
+			Expect(excludeError(errors.Wrap(ctx, stderrors.New("connection reset"), "call"))).
+				To(BeTrue())
+			Expect(excludeError(stderrors.New("banana"))).To(BeFalse())
+			Expect(excludeError(nil)).To(BeFalse())
+		})
+	})
+	Context("combinators", func() {
+		It("and", func() {
+			Expect(sentry.ExcludeErrorAnd()(nil)).To(BeFalse())
+			Expect(sentry.ExcludeErrorAnd(yes, yes)(nil)).To(BeTrue())
+			Expect(sentry.ExcludeErrorAnd(yes, no)(nil)).To(BeFalse())
+		})
+		It("or", func() {
+			Expect(sentry.ExcludeErrorOr()(nil)).To(BeFalse())
+			Expect(sentry.ExcludeErrorOr(no, yes)(nil)).To(BeTrue())
+			Expect(sentry.ExcludeErrorOr(no, no)(nil)).To(BeFalse())
+		})
+		It("not", func() {
+			Expect(sentry.ExcludeErrorNot(yes)(nil)).To(BeFalse())
+			Expect(sentry.ExcludeErrorNot(no)(nil)).To(BeTrue())
+		})
+		It("composes", func() {
+			excludeError := sentry.ExcludeErrorAnd(
+				sentry.ExcludeErrorIs(context.Canceled),
+				sentry.ExcludeErrorNot(sentry.ExcludeErrorMessageRegexp(regexp.MustCompile("critical"))),
+			)
+			Expect(excludeError(errors.Wrap(ctx, context.Canceled, "job"))).To(BeTrue())
+			Expect(excludeError(errors.Wrap(ctx, context.Canceled, "critical job"))).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// ExcludeEvents is a collection of ExcludeEvent functions.
+type ExcludeEvents []ExcludeEvent
+
+// IsExcluded checks if the given event matches any of the exclude conditions.
+func (e ExcludeEvents) IsExcluded(event *sentry.Event, hint *sentry.EventHint) bool {
+	_, ok := e.match(event, hint)
+	return ok
+}
+
+// match returns the first ExcludeEvent returning true for the given event.
+func (e ExcludeEvents) match(event *sentry.Event, hint *sentry.EventHint) (ExcludeEvent, bool) {
+	for _, ee := range e {
+		if ee(event, hint) {
+			return ee, true
+		}
+	}
+	return nil, false
+}
+
+// ExcludeEvent determines whether an event should be dropped before it is sent to Sentry.
+// It is evaluated after tag enrichment for exceptions and messages, so it sees the final
+// level, tags, logger and fingerprint. It returns true if the event should be excluded.
+type ExcludeEvent func(event *sentry.Event, hint *sentry.EventHint) bool
+
+// ExcludeEventLevel excludes events with any of the given levels.
+//
+//	sentry.ExcludeEventLevel(sentry.LevelDebug, sentry.LevelInfo)
+func ExcludeEventLevel(levels ...sentry.Level) ExcludeEvent {
+	return func(event *sentry.Event, hint *sentry.EventHint) bool {
+		return slices.Contains(levels, event.Level)
+	}
+}
+
+// ExcludeEventTag excludes events with a tag of the given key and any of the given values.
+// Without values every event with the tag is excluded.
+//
+//	sentry.ExcludeEventTag("expected", "true")
+func ExcludeEventTag(key string, values ...string) ExcludeEvent {
+	return func(event *sentry.Event, hint *sentry.EventHint) bool {
+		value, ok := event.Tags[key]
+		if !ok {
+			return false
+		}
+		return len(values) == 0 || slices.Contains(values, value)
+	}
+}
+
+// ExcludeEventLogger excludes events of any of the given loggers.
+func ExcludeEventLogger(loggers ...string) ExcludeEvent {
+	return func(event *sentry.Event, hint *sentry.EventHint) bool {
+		return slices.Contains(loggers, event.Logger)
+	}
+}
+
+// ExcludeEventFingerprint excludes events with exactly the given fingerprint.
+func ExcludeEventFingerprint(fingerprint ...string) ExcludeEvent {
+	return func(event *sentry.Event, hint *sentry.EventHint) bool {
+		return len(event.Fingerprint) > 0 && slices.Equal(event.Fingerprint, fingerprint)
+	}
+}
+
+// ExcludeEventMessageRegexp excludes events whose message matches any of the given
+// regular expressions.
+func ExcludeEventMessageRegexp(regexps ...*regexp.Regexp) ExcludeEvent {
+	return func(event *sentry.Event, hint *sentry.EventHint) bool {
+		if event.Message == "" {
+			return false
+		}
+		for _, re := range regexps {
+			if re.MatchString(event.Message) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// newExcludeEventProcessor creates an event processor that drops excluded events and calls
+// onExcluded with their hint. Transactions and check-ins are never dropped.
+func newExcludeEventProcessor(
+	excludeEvents ExcludeEvents,
+	metrics Metrics,
+	onExcluded func(hint *sentry.EventHint),
+) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Type != "" {
+			return event
+		}
+		if excludeEvent, ok := excludeEvents.match(event, hint); ok {
+			glog.V(4).Infof("capture event %s is excluded => skip", event.EventID)
+			metrics.ExcludedInc(matcherName(excludeEvent))
+			onExcluded(hint)
+			return nil
+		}
+		return event
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"regexp"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("ExcludeEvent", func() {
+	var event *libsentry.Event
+	BeforeEach(func() {
+		event = &libsentry.Event{
+			Level:       libsentry.LevelWarning,
+			Logger:      "kafka",
+			Message:     "consumer lag high",
+			Fingerprint: []string{"lag"},
+			Tags:        map[string]string{"expected": "true"},
+		}
+	})
+	DescribeTable("builders",
+		func(excludeEvent sentry.ExcludeEvent, expected bool) {
+			Expect(excludeEvent(event, &libsentry.EventHint{})).To(Equal(expected))
+		},
+		Entry("level match", sentry.ExcludeEventLevel(libsentry.LevelWarning), true),
+		Entry("level no match", sentry.ExcludeEventLevel(libsentry.LevelError), false),
+		Entry("tag match", sentry.ExcludeEventTag("expected", "true"), true),
+		Entry("tag any value", sentry.ExcludeEventTag("expected"), true),
+		Entry("tag other value", sentry.ExcludeEventTag("expected", "false"), false),
+		Entry("tag missing", sentry.ExcludeEventTag("missing"), false),
+		Entry("logger match", sentry.ExcludeEventLogger("kafka"), true),
+		Entry("logger no match", sentry.ExcludeEventLogger("http"), false),
+		Entry("fingerprint match", sentry.ExcludeEventFingerprint("lag"), true),
+		Entry("fingerprint no match", sentry.ExcludeEventFingerprint("lag", "x"), false),
+		Entry("message match", sentry.ExcludeEventMessageRegexp(regexp.MustCompile("lag")), true),
+		Entry("message no match", sentry.ExcludeEventMessageRegexp(regexp.MustCompile("^x")), false),
+	)
+	Context("client", func() {
+		var ctx context.Context
+		var recorder *sentrytest.Recorder
+		BeforeEach(func() {
+			ctx = context.Background()
+			recorder = sentrytest.NewRecorderWithOptions(func(options *sentry.Options) {
+				options.ExcludeErrors = sentry.ExcludeErrors{
+					sentry.ExcludeErrorIs(context.Canceled),
+				}
+				options.ExcludeEvents = sentry.ExcludeEvents{
+					sentry.ExcludeEventTag("expected", "true"),
+					sentry.ExcludeEventLevel(libsentry.LevelDebug),
+				}
+			})
+		})
+		It("drops exception with enriched tag", func() {
+			Expect(recorder.CaptureException(
+				stderrors.New("banana"),
+				&libsentry.EventHint{Context: errors.AddToContext(ctx, "expected", "true")},
+				nil,
+			)).To(BeNil())
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+		It("drops message by level", func() {
+			scope := libsentry.NewScope()
+			scope.SetLevel(libsentry.LevelDebug)
+			Expect(recorder.CaptureMessage("hello", nil, scope)).To(BeNil())
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+		It("drops message with excluded original exception", func() {
+			Expect(recorder.CaptureMessage(
+				"hello",
+				&libsentry.EventHint{OriginalException: context.Canceled},
+				nil,
+			)).To(BeNil())
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+		It("captures other messages", func() {
+			Expect(recorder.CaptureMessage("hello", nil, nil)).NotTo(BeNil())
+			Expect(recorder).To(sentrytest.HaveCapturedMessage("hello"))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// TagProvider can be implemented by hint data and data values to contribute their own tags.
+// Tags of a nested value are prefixed with its key and a dot.
+//
+//	func (o Order) SentryTags() map[string]string {
+//	    return map[string]string{"order_id": o.ID}
+//	}
+type TagProvider interface {
+	SentryTags() map[string]string
+}
+
+// hintData converts hint.Data into a map. Supported are maps with string like keys, structs
+// (field names from `sentry` or `json` struct tags) and TagProvider. Nil values are skipped.
+func hintData(hint *sentry.EventHint) map[string]any {
+	data, ok := toDataMap(hint.Data)
+	if !ok {
+		return nil
+	}
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		if v == nil {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// toDataMap converts the given value into a map if it is a TagProvider, a map with string
+// like keys or a struct.
+func toDataMap(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case map[string]any:
+		return v, true
+	case TagProvider:
+		if isNilValue(reflect.ValueOf(v)) {
+			return nil, false
+		}
+		tags := v.SentryTags()
+		result := make(map[string]any, len(tags))
+		for k, vv := range tags {
+			result[k] = vv
+		}
+		return result, true
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		result := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = iter.Value().Interface()
+		}
+		return result, true
+	case reflect.Struct:
+		result := make(map[string]any)
+		addStructFields(result, rv)
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// addStructFields adds the exported fields of the given struct. Fields tagged with "-" are
+// skipped, zero values of fields tagged with omitempty too. Embedded structs without name
+// are merged into the result.
+func addStructFields(result map[string]any, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty, skip := structFieldName(field)
+		if skip {
+			continue
+		}
+		value := rv.Field(i)
+		if omitEmpty && value.IsZero() {
+			continue
+		}
+		if name == "" && field.Anonymous && reflect.Indirect(value).Kind() == reflect.Struct {
+			if value.Kind() == reflect.Pointer && value.IsNil() {
+				continue
+			}
+			addStructFields(result, reflect.Indirect(value))
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if isNilValue(value) {
+			continue
+		}
+		result[name] = value.Interface()
+	}
+}
+
+// structFieldName returns the name of the field from its `sentry` or `json` struct tag.
+func structFieldName(field reflect.StructField) (string, bool, bool) {
+	for _, key := range []string{"sentry", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if tag == "-" {
+			return "", false, true
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		return name, strings.Contains(options, "omitempty"), false
+	}
+	return "", false, false
+}
+
+func isNilValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// formatTagValue formats a value that is not flattened into a tag value. Nil pointers are
+// formatted by fmt, because calling their String or Error method can panic.
+func formatTagValue(value any) string {
+	if isNilValue(reflect.ValueOf(value)) {
+		return fmt.Sprintf("%v", value)
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
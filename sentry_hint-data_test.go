@@ -0,0 +1,124 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	stderrors "errors"
+	"strings"
+	"time"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+type hintDataOrder struct {
+	ID       string `sentry:"order_id"`
+	Customer string `json:"customer"`
+	Amount   int
+	Note     string    `json:"note,omitempty"`
+	Secret   string    `json:"-"`
+	Created  time.Time `json:"created"`
+	internal string
+}
+
+type hintDataTenant string
+
+var _ sentry.TagProvider = hintDataTenant("")
+
+func (t hintDataTenant) SentryTags() map[string]string {
+	return map[string]string{"tenant": string(t), "tenant_type": "customer"}
+}
+
+type hintDataVersion struct{}
+
+func (v hintDataVersion) String() string {
+	return "v1.2"
+}
+
+type hintDataNode struct {
+	Name   string        `json:"name"`
+	Parent *hintDataNode `json:"parent"`
+}
+
+var _ = Describe("Hint data", func() {
+	var recorder *sentrytest.Recorder
+	BeforeEach(func() {
+		recorder = sentrytest.NewRecorder()
+	})
+	tagsFor := func(data any) map[string]string {
+		recorder.Reset()
+		recorder.CaptureException(stderrors.New("banana"), &libsentry.EventHint{Data: data}, nil)
+		Expect(recorder.Events()).To(HaveLen(1))
+		return recorder.Events()[0].Tags
+	}
+	It("supports typed maps", func() {
+		Expect(tagsFor(map[string]int{"count": 42})).To(HaveKeyWithValue("count", "42"))
+	})
+	It("supports maps with string like keys", func() {
+		type key string
+		Expect(tagsFor(map[key]bool{"cached": true})).To(HaveKeyWithValue("cached", "true"))
+	})
+	It("ignores maps with other keys", func() {
+		Expect(tagsFor(map[int]string{1: "one"})).NotTo(HaveKey("1"))
+	})
+	It("supports structs with struct tags", func() {
+		created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		tags := tagsFor(&hintDataOrder{
+			ID:       "o-1",
+			Customer: "c-1",
+			Amount:   7,
+			Secret:   "hunter2",
+			Created:  created,
+			internal: "x",
+		})
+		Expect(tags).To(HaveKeyWithValue("order_id", "o-1"))
+		Expect(tags).To(HaveKeyWithValue("customer", "c-1"))
+		Expect(tags).To(HaveKeyWithValue("Amount", "7"))
+		Expect(tags).To(HaveKeyWithValue("created", created.String()))
+		Expect(tags).NotTo(HaveKey("note"))
+		Expect(tags).NotTo(HaveKey("Secret"))
+		Expect(tags).NotTo(HaveKey("internal"))
+	})
+	It("supports TagProvider", func() {
+		tags := tagsFor(hintDataTenant("t-1"))
+		Expect(tags).To(HaveKeyWithValue("tenant", "t-1"))
+		Expect(tags).To(HaveKeyWithValue("tenant_type", "customer"))
+	})
+	It("flattens nested TagProvider with key prefix", func() {
+		tags := tagsFor(map[string]any{"owner": hintDataTenant("t-1")})
+		Expect(tags).To(HaveKeyWithValue("owner.tenant", "t-1"))
+	})
+	It("formats fmt.Stringer values", func() {
+		tags := tagsFor(map[string]any{"version": hintDataVersion{}})
+		Expect(tags).To(HaveKeyWithValue("version", "v1.2"))
+	})
+	It("formats nil fmt.Stringer values without panic", func() {
+		tags := tagsFor(map[string]any{"created": (*time.Time)(nil)})
+		Expect(tags).To(HaveKeyWithValue("created", "<nil>"))
+	})
+	It("formats self referencing structs without endless recursion", func() {
+		node := &hintDataNode{Name: "root"}
+		node.Parent = node
+		tags := tagsFor(map[string]any{"node": node})
+		Expect(tags).To(HaveKeyWithValue("node.name", "root"))
+		Expect(tags).To(HaveKey("node.parent"))
+		Expect(tags).NotTo(HaveKey("node.parent.name"))
+	})
+	It("formats values nested deeper than the max depth", func() {
+		var data any = "leaf"
+		for i := 0; i < 20; i++ {
+			data = map[string]any{"n": data}
+		}
+		tags := tagsFor(map[string]any{"deep": data})
+		Expect(tags).To(HaveLen(1))
+		for key := range tags {
+			Expect(strings.Count(key, ".")).To(Equal(10))
+		}
+	})
+})
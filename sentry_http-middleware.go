@@ -0,0 +1,327 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// DefaultHTTPHeaderDenylist contains the fragments of header names (case insensitive) that
+// are never attached to Sentry events by the HTTP middleware, e.g. "auth" removes
+// Authorization, Proxy-Authorization and X-Auth-Token.
+var DefaultHTTPHeaderDenylist = []string{
+	"auth",
+	"cookie",
+	"key",
+	"password",
+	"secret",
+	"token",
+	"X-Forwarded-For",
+	"X-Real-Ip",
+}
+
+// DefaultHTTPQueryDenylist contains the fragments of query parameter names (case
+// insensitive) whose values are filtered by the HTTP middleware.
+var DefaultHTTPQueryDenylist = []string{
+	"auth",
+	"key",
+	"password",
+	"secret",
+	"token",
+}
+
+// HTTPMiddlewareOptions configures the behaviour of NewHTTPMiddleware and NewHTTPErrorHandler.
+type HTTPMiddlewareOptions struct {
+	// HeaderDenylist contains fragments of header names (case insensitive). Matching headers
+	// are removed from the request attached to events.
+	HeaderDenylist []string
+	// QueryDenylist contains fragments of query parameter names (case insensitive). Values
+	// of matching parameters are replaced with [Filtered] in the request attached to events.
+	QueryDenylist []string
+	// Repanic re-raises a recovered panic after it was reported.
+	// If false the middleware responds with status 500.
+	Repanic bool
+	// CaptureStatus decides which response status codes are reported as events.
+	// Defaults to all status codes >= 500.
+	CaptureStatus func(statusCode int) bool
+}
+
+// HTTPHandlerWithError is a http handler that can return an error.
+type HTTPHandlerWithError interface {
+	ServeHTTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) error
+}
+
+// HTTPHandlerWithErrorFunc allows using a function as HTTPHandlerWithError.
+type HTTPHandlerWithErrorFunc func(ctx context.Context, resp http.ResponseWriter, req *http.Request) error
+
+// ServeHTTP implements HTTPHandlerWithError.
+func (h HTTPHandlerWithErrorFunc) ServeHTTP(
+	ctx context.Context,
+	resp http.ResponseWriter,
+	req *http.Request,
+) error {
+	return h(ctx, resp, req)
+}
+
+// NewHTTPMiddleware wraps the given handler and reports panics and responses with
+// status >= 500 to Sentry. Each request gets its own scope stored in the request context,
+// containing the sanitized request and request tags. Captures with this context as
+// hint.Context inherit the scope:
+//
+//	sentryClient.CaptureException(err, &sentry.EventHint{Context: req.Context()}, nil)
+//
+// A panic with http.ErrAbortHandler is re-raised without reporting, like net/http does.
+func NewHTTPMiddleware(
+	sentryClient Client,
+	handler http.Handler,
+	optionFns ...func(options *HTTPMiddlewareOptions),
+) http.Handler {
+	options := HTTPMiddlewareOptions{
+		HeaderDenylist: DefaultHTTPHeaderDenylist,
+		QueryDenylist:  DefaultHTTPQueryDenylist,
+		CaptureStatus: func(statusCode int) bool {
+			return statusCode >= http.StatusInternalServerError
+		},
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	return &httpMiddleware{
+		sentryClient: sentryClient,
+		handler:      handler,
+		options:      options,
+	}
+}
+
+// NewHTTPErrorHandler creates a http.Handler for the given HTTPHandlerWithError.
+// A returned error is reported to Sentry and answered with status 500 unless the handler
+// already wrote the response header.
+// Panics and other responses with status >= 500 are handled like in NewHTTPMiddleware.
+func NewHTTPErrorHandler(
+	sentryClient Client,
+	handler HTTPHandlerWithError,
+	optionFns ...func(options *HTTPMiddlewareOptions),
+) http.Handler {
+	return NewHTTPMiddleware(
+		sentryClient,
+		http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+			err := handler.ServeHTTP(ctx, resp, req)
+			if err == nil {
+				return
+			}
+			glog.V(2).Infof("handle %s %s failed: %v", req.Method, req.URL.Path, err)
+			sentryClient.CaptureException(
+				err,
+				&sentry.EventHint{
+					Context: ctx,
+					Request: req,
+				},
+				sentry.NewScope(),
+			)
+			writer, ok := resp.(*statusResponseWriter)
+			if ok {
+				writer.reported = true
+				if writer.wroteHeader {
+					// the response is already sent, appending an error would corrupt it
+					return
+				}
+			}
+			http.Error(
+				resp,
+				http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError,
+			)
+		}),
+		optionFns...,
+	)
+}
+
+type httpMiddleware struct {
+	sentryClient Client
+	handler      http.Handler
+	options      HTTPMiddlewareOptions
+}
+
+func (h *httpMiddleware) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	scope := sentry.NewScope()
+	scope.SetTag("http.method", req.Method)
+	scope.SetTag("http.path", req.URL.Path)
+	request := newSanitizedRequest(req, h.options.HeaderDenylist, h.options.QueryDenylist)
+	scope.AddEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		if event.Request == nil {
+			event.Request = request
+		}
+		return event
+	})
+	ctx := sentry.SetHubOnContext(req.Context(), sentry.NewHub(nil, scope))
+	req = req.WithContext(ctx)
+
+	writer := &statusResponseWriter{ResponseWriter: resp}
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			h.captureStatus(ctx, req, writer)
+			return
+		}
+		if recovered == http.ErrAbortHandler {
+			// aborts the response on purpose, net/http suppresses it as well
+			panic(recovered)
+		}
+		_ = reportPanic(ctx, h.sentryClient, recovered, sentry.NewStacktrace(), sentry.NewScope())
+		if h.options.Repanic {
+			panic(recovered)
+		}
+		if !writer.wroteHeader {
+			http.Error(
+				writer,
+				http.StatusText(http.StatusInternalServerError),
+				http.StatusInternalServerError,
+			)
+		}
+	}()
+	h.handler.ServeHTTP(writer, req)
+}
+
+func (h *httpMiddleware) captureStatus(
+	ctx context.Context,
+	req *http.Request,
+	writer *statusResponseWriter,
+) {
+	if writer.reported || !h.options.CaptureStatus(writer.Status()) {
+		return
+	}
+	scope := sentry.NewScope()
+	scope.SetTag("http.status_code", strconv.Itoa(writer.Status()))
+	scope.SetLevel(sentry.LevelError)
+	h.sentryClient.CaptureMessage(
+		fmt.Sprintf("%s %s responded with status %d", req.Method, req.URL.Path, writer.Status()),
+		&sentry.EventHint{
+			Context: ctx,
+			Request: req,
+		},
+		scope,
+	)
+}
+
+// newSanitizedRequest converts the given http.Request into a sentry.Request without the
+// headers of the header denylist and with filtered values of the query denylist. The body is
+// never read.
+func newSanitizedRequest(
+	req *http.Request,
+	headerDenylist []string,
+	queryDenylist []string,
+) *sentry.Request {
+	headers := make(map[string]string, len(req.Header)+1)
+	for key, values := range req.Header {
+		if containsFragment(key, headerDenylist) {
+			continue
+		}
+		headers[key] = strings.Join(values, ",")
+	}
+	headers["Host"] = req.Host
+
+	scheme := "http"
+	if req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return &sentry.Request{
+		URL:         fmt.Sprintf("%s://%s%s", scheme, req.Host, req.URL.Path),
+		Method:      req.Method,
+		QueryString: filterQuery(req.URL.RawQuery, queryDenylist),
+		Headers:     headers,
+	}
+}
+
+// filterQuery replaces the values of parameters of the denylist in the given raw query and
+// keeps all other parameters unchanged.
+func filterQuery(rawQuery string, denylist []string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		rawKey, _, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if containsFragment(key, denylist) {
+			params[i] = rawKey + "=" + url.QueryEscape("[Filtered]")
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// containsFragment returns true if the name contains any of the fragments, ignoring case.
+func containsFragment(name string, fragments []string) bool {
+	name = strings.ToLower(name)
+	for _, fragment := range fragments {
+		if strings.Contains(name, strings.ToLower(fragment)) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusResponseWriter records the status code written by the wrapped handler.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	reported    bool
+}
+
+func (w *statusResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.status = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Status returns the written status code, http.StatusOK if nothing was written.
+func (w *statusResponseWriter) Status() int {
+	if !w.wroteHeader {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Flush implements http.Flusher for streaming handlers. It does nothing if the original
+// http.ResponseWriter does not support flushing.
+func (w *statusResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker, e.g. for websockets.
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap allows http.ResponseController to access the original http.ResponseWriter.
+func (w *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("HTTPMiddleware", func() {
+	var recorder *sentrytest.Recorder
+	var handler http.Handler
+	var resp *httptest.ResponseRecorder
+	var req *http.Request
+	BeforeEach(func() {
+		recorder = sentrytest.NewRecorder()
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest(
+			http.MethodGet,
+			"http://example.com/path?a=b&access_token=abc&Password=hunter2",
+			nil,
+		)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Session-Token", "secret")
+		req.Header.Set("X-Custom-Api-Key", "secret")
+		req.Header.Set("X-Custom", "custom")
+	})
+	JustBeforeEach(func() {
+		sentry.NewHTTPMiddleware(recorder, handler).ServeHTTP(resp, req)
+	})
+	Context("success", func() {
+		BeforeEach(func() {
+			handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte("ok"))
+			})
+		})
+		It("captures nothing", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+	})
+	Context("status 4xx", func() {
+		BeforeEach(func() {
+			handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusNotFound)
+			})
+		})
+		It("captures nothing", func() {
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+	})
+	Context("status 5xx", func() {
+		BeforeEach(func() {
+			handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusBadGateway)
+			})
+		})
+		It("captures message", func() {
+			Expect(resp.Code).To(Equal(http.StatusBadGateway))
+			Expect(recorder).To(sentrytest.HaveCapturedMessage("GET /path responded with status 502"))
+			event := recorder.Events()[0]
+			Expect(event.Level).To(Equal(libsentry.LevelError))
+			Expect(event).To(sentrytest.HaveTag("http.status_code", "502"))
+			Expect(event).To(sentrytest.HaveTag("http.method", "GET"))
+		})
+		It("attaches sanitized request", func() {
+			request := recorder.Events()[0].Request
+			Expect(request).NotTo(BeNil())
+			Expect(request.URL).To(Equal("http://example.com/path"))
+			Expect(request.Headers).To(HaveKeyWithValue("X-Custom", "custom"))
+			Expect(request.Headers).NotTo(HaveKey("Authorization"))
+		})
+		It("removes headers containing a denied fragment", func() {
+			request := recorder.Events()[0].Request
+			Expect(request.Headers).NotTo(HaveKey("X-Session-Token"))
+			Expect(request.Headers).NotTo(HaveKey("X-Custom-Api-Key"))
+		})
+		It("filters tokens in the query string", func() {
+			request := recorder.Events()[0].Request
+			Expect(request.QueryString).To(Equal(
+				"a=b&access_token=%5BFiltered%5D&Password=%5BFiltered%5D",
+			))
+		})
+	})
+	Context("capture inside handler", func() {
+		BeforeEach(func() {
+			handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				recorder.CaptureException(
+					stderrors.New("banana"),
+					&libsentry.EventHint{Context: req.Context()},
+					nil,
+				)
+			})
+		})
+		It("inherits request scope", func() {
+			Expect(recorder).To(sentrytest.HaveCapturedException("banana"))
+			event := recorder.Events()[0]
+			Expect(event).To(sentrytest.HaveTag("http.path", "/path"))
+			Expect(event.Request).NotTo(BeNil())
+		})
+	})
+	Context("panic", func() {
+		BeforeEach(func() {
+			handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				panic("banana")
+			})
+		})
+		It("responds with 500", func() {
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		})
+		It("captures fatal exception with request", func() {
+			Expect(recorder).To(sentrytest.HaveCapturedException("panic: banana"))
+			Expect(recorder.Events()).To(HaveLen(1))
+			event := recorder.Events()[0]
+			Expect(event.Level).To(Equal(libsentry.LevelFatal))
+			Expect(event.Request).NotTo(BeNil())
+		})
+	})
+	Context("streaming", func() {
+		BeforeEach(func() {
+			handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				flusher, ok := resp.(http.Flusher)
+				Expect(ok).To(BeTrue())
+				_, _ = resp.Write([]byte("chunk"))
+				flusher.Flush()
+			})
+		})
+		It("flushes the original writer", func() {
+			Expect(resp.Flushed).To(BeTrue())
+			Expect(resp.Body.String()).To(Equal("chunk"))
+		})
+	})
+})
+
+var _ = Describe("HTTPMiddleware abort", func() {
+	It("re-panics http.ErrAbortHandler without reporting", func() {
+		recorder := sentrytest.NewRecorder()
+		handler := sentry.NewHTTPMiddleware(
+			recorder,
+			http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				panic(http.ErrAbortHandler)
+			}),
+		)
+		resp := httptest.NewRecorder()
+		Expect(func() {
+			handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+		}).To(PanicWith(http.ErrAbortHandler))
+		Expect(recorder.Events()).To(BeEmpty())
+	})
+	It("supports hijacking", func() {
+		server := httptest.NewServer(sentry.NewHTTPMiddleware(
+			sentrytest.NewRecorder(),
+			http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				hijacker, ok := resp.(http.Hijacker)
+				Expect(ok).To(BeTrue())
+				conn, buf, err := hijacker.Hijack()
+				Expect(err).To(BeNil())
+				defer conn.Close()
+				_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\nhijacked")
+				_ = buf.Flush()
+			}),
+		))
+		defer server.Close()
+		response, err := http.Get(server.URL)
+		Expect(err).To(BeNil())
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		Expect(err).To(BeNil())
+		Expect(string(body)).To(Equal("hijacked"))
+	})
+})
+
+var _ = Describe("HTTPErrorHandler", func() {
+	var recorder *sentrytest.Recorder
+	var resp *httptest.ResponseRecorder
+	var handlerErr error
+	var written string
+	BeforeEach(func() {
+		recorder = sentrytest.NewRecorder()
+		resp = httptest.NewRecorder()
+		written = ""
+	})
+	JustBeforeEach(func() {
+		sentry.NewHTTPErrorHandler(
+			recorder,
+			sentry.HTTPHandlerWithErrorFunc(
+				func(ctx context.Context, resp http.ResponseWriter, req *http.Request) error {
+					if written != "" {
+						_, _ = resp.Write([]byte(written))
+					}
+					return handlerErr
+				},
+			),
+		).ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/", nil))
+	})
+	Context("success", func() {
+		BeforeEach(func() {
+			handlerErr = nil
+		})
+		It("captures nothing", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+	})
+	Context("error", func() {
+		BeforeEach(func() {
+			handlerErr = stderrors.New("banana")
+		})
+		It("responds with 500", func() {
+			Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+		})
+		It("captures the error once", func() {
+			Expect(recorder.Events()).To(HaveLen(1))
+			Expect(recorder).To(sentrytest.HaveCapturedException("banana"))
+			Expect(recorder.Events()[0]).To(sentrytest.HaveTag("http.method", "POST"))
+		})
+	})
+	Context("error after response started", func() {
+		BeforeEach(func() {
+			handlerErr = stderrors.New("banana")
+			written = "partial"
+		})
+		It("keeps the response", func() {
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			Expect(resp.Body.String()).To(Equal("partial"))
+		})
+		It("captures the error once", func() {
+			Expect(recorder.Events()).To(HaveLen(1))
+			Expect(recorder).To(sentrytest.HaveCapturedException("banana"))
+		})
+	})
+})
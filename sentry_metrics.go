@@ -0,0 +1,237 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	stdtime "time"
+
+	"github.com/bborbe/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Kinds of captures counted by Metrics.
+const (
+	CaptureKindException = "exception"
+	CaptureKindMessage   = "message"
+	CaptureKindCheckIn   = "check_in"
+)
+
+// Metrics records the telemetry of a client. Create it with NewMetrics and pass it with
+// Options.Metrics.
+type Metrics interface {
+	// CaptureInc counts a call of CaptureException, CaptureMessage or CaptureCheckIn.
+	CaptureInc(kind string)
+	// ExcludedInc counts an event excluded by the named ExcludeError, ExcludeErrorHint or
+	// ExcludeEvent.
+	ExcludedInc(matcher string)
+	// EventIDNilInc counts a capture that returned no event id although the client neither
+	// excluded nor dropped it, e.g. because of sampling or BeforeSend.
+	EventIDNilInc(kind string)
+	// DroppedInc counts an event dropped for the given reason, e.g. after Close.
+	DroppedInc(reason string)
+	// TransportErrorInc counts a failed request to Sentry by HTTP status or "error" if no
+	// response was received.
+	TransportErrorInc(status string)
+	// PendingEventsAdd changes the number of events passed to the transport whose delivery
+	// is not confirmed yet by a successful request to Sentry or a successful flush. Clients
+	// sharing the collectors add their own changes, so the gauge is the sum of all clients.
+	PendingEventsAdd(delta int64)
+	// FlushDurationObserve records the duration of a Flush or Close.
+	FlushDurationObserve(duration stdtime.Duration, delivered bool)
+}
+
+// NewMetrics creates Metrics whose collectors are registered at the given registerer, e.g.
+// prometheus.DefaultRegisterer. Collectors already registered by another client are
+// shared, so multiple clients can use the same registerer.
+//
+//	metrics, err := sentry.NewMetrics(ctx, prometheus.DefaultRegisterer)
+//	client, err := sentry.NewClientWithOptions(ctx, clientOptions, func(options *sentry.Options) {
+//	    options.Metrics = metrics
+//	})
+func NewMetrics(ctx context.Context, registerer prometheus.Registerer) (Metrics, error) {
+	m := &metrics{
+		captures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "captures_total",
+			Help:      "Number of captured exceptions, messages and check-ins.",
+		}, []string{"kind"}),
+		excluded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "excluded_total",
+			Help:      "Number of events excluded by exclude functions.",
+		}, []string{"matcher"}),
+		eventIDNil: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "event_id_nil_total",
+			Help:      "Number of captures that returned no event id.",
+		}, []string{"kind"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "dropped_total",
+			Help:      "Number of events dropped by the client.",
+		}, []string{"reason"}),
+		transportErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "transport_errors_total",
+			Help:      "Number of failed requests to Sentry by HTTP status.",
+		}, []string{"status"}),
+		pendingEvents: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "pending_events",
+			Help:      "Number of events passed to the transport and not delivered yet.",
+		}),
+		flushDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "sentry",
+			Subsystem: "client",
+			Name:      "flush_duration_seconds",
+			Help:      "Duration of flushes by result.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"result"}),
+	}
+	var err error
+	if m.captures, err = register(ctx, registerer, m.captures); err != nil {
+		return nil, err
+	}
+	if m.excluded, err = register(ctx, registerer, m.excluded); err != nil {
+		return nil, err
+	}
+	if m.eventIDNil, err = register(ctx, registerer, m.eventIDNil); err != nil {
+		return nil, err
+	}
+	if m.dropped, err = register(ctx, registerer, m.dropped); err != nil {
+		return nil, err
+	}
+	if m.transportErrors, err = register(ctx, registerer, m.transportErrors); err != nil {
+		return nil, err
+	}
+	if m.pendingEvents, err = register(ctx, registerer, m.pendingEvents); err != nil {
+		return nil, err
+	}
+	if m.flushDuration, err = register(ctx, registerer, m.flushDuration); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers the given collector or returns the collector registered already.
+func register[T prometheus.Collector](
+	ctx context.Context,
+	registerer prometheus.Registerer,
+	collector T,
+) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return collector, errors.Wrapf(ctx, err, "register sentry metrics failed")
+	}
+	return collector, nil
+}
+
+type metrics struct {
+	captures        *prometheus.CounterVec
+	excluded        *prometheus.CounterVec
+	eventIDNil      *prometheus.CounterVec
+	dropped         *prometheus.CounterVec
+	transportErrors *prometheus.CounterVec
+	pendingEvents   prometheus.Gauge
+	flushDuration   *prometheus.HistogramVec
+}
+
+func (m *metrics) CaptureInc(kind string) {
+	m.captures.WithLabelValues(kind).Inc()
+}
+
+func (m *metrics) ExcludedInc(matcher string) {
+	m.excluded.WithLabelValues(matcher).Inc()
+}
+
+func (m *metrics) EventIDNilInc(kind string) {
+	m.eventIDNil.WithLabelValues(kind).Inc()
+}
+
+func (m *metrics) DroppedInc(reason string) {
+	m.dropped.WithLabelValues(reason).Inc()
+}
+
+func (m *metrics) TransportErrorInc(status string) {
+	m.transportErrors.WithLabelValues(status).Inc()
+}
+
+func (m *metrics) PendingEventsAdd(delta int64) {
+	m.pendingEvents.Add(float64(delta))
+}
+
+func (m *metrics) FlushDurationObserve(duration stdtime.Duration, delivered bool) {
+	result := "timeout"
+	if delivered {
+		result = "delivered"
+	}
+	m.flushDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// noopMetrics is used by clients without Options.Metrics.
+type noopMetrics struct{}
+
+func (noopMetrics) CaptureInc(kind string)                                         {}
+func (noopMetrics) ExcludedInc(matcher string)                                     {}
+func (noopMetrics) EventIDNilInc(kind string)                                      {}
+func (noopMetrics) DroppedInc(reason string)                                       {}
+func (noopMetrics) TransportErrorInc(status string)                                {}
+func (noopMetrics) PendingEventsAdd(delta int64)                                   {}
+func (noopMetrics) FlushDurationObserve(duration stdtime.Duration, delivered bool) {}
+
+// closureSuffix matches the suffix of the names of anonymous functions, e.g. ".func1" or
+// ".func1.1" of inlined ones, and "-fm" of method values.
+var closureSuffix = regexp.MustCompile(`(\.func\d+|\.\d+|-fm)+$`)
+
+// matcherName returns the name of the function creating the given exclude function, e.g.
+// "sentry.ExcludeErrorIs", which keeps the cardinality of the matcher label low.
+func matcherName(fn any) string {
+	function := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if function == nil {
+		return "unknown"
+	}
+	name := function.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return closureSuffix.ReplaceAllString(name, "")
+}
+
+// newMetricsRoundTripper creates an http.RoundTripper counting failed requests.
+func newMetricsRoundTripper(roundTripper http.RoundTripper, metrics Metrics) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := roundTripper.RoundTrip(req)
+		if err != nil {
+			metrics.TransportErrorInc("error")
+			return nil, err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			metrics.TransportErrorInc(strconv.Itoa(resp.StatusCode))
+		}
+		return resp, nil
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
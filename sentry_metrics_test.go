@@ -0,0 +1,197 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/bborbe/sentry"
+)
+
+var _ = Describe("Metrics", func() {
+	var ctx context.Context
+	var registry *prometheus.Registry
+	var clientOptions libsentry.ClientOptions
+	var client sentry.Client
+	BeforeEach(func() {
+		ctx = context.Background()
+		registry = prometheus.NewRegistry()
+		clientOptions = libsentry.ClientOptions{}
+	})
+	JustBeforeEach(func() {
+		metrics, err := sentry.NewMetrics(ctx, registry)
+		Expect(err).To(BeNil())
+		client, err = sentry.NewClientWithOptions(ctx, clientOptions, func(options *sentry.Options) {
+			options.Metrics = metrics
+			options.CloseTimeout = time.Second
+			options.ExcludeErrors = sentry.ExcludeErrors{sentry.ExcludeErrorIs(context.Canceled)}
+			options.ExcludeEvents = sentry.ExcludeEvents{sentry.ExcludeEventLevel(libsentry.LevelDebug)}
+		})
+		Expect(err).To(BeNil())
+	})
+	// value returns the value of the metric with the given name and label value
+	value := func(name string, labelValue string) float64 {
+		families, err := registry.Gather()
+		Expect(err).To(BeNil())
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				labels := metric.GetLabel()
+				if labelValue != "" && (len(labels) == 0 || labels[0].GetValue() != labelValue) {
+					continue
+				}
+				switch {
+				case metric.GetCounter() != nil:
+					return metric.GetCounter().GetValue()
+				case metric.GetGauge() != nil:
+					return metric.GetGauge().GetValue()
+				case metric.GetHistogram() != nil:
+					return float64(metric.GetHistogram().GetSampleCount())
+				}
+			}
+		}
+		return 0
+	}
+	It("counts captures by kind", func() {
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		client.CaptureMessage("banana", nil, nil)
+		Expect(value("sentry_client_captures_total", sentry.CaptureKindException)).To(Equal(2.0))
+		Expect(value("sentry_client_captures_total", sentry.CaptureKindMessage)).To(Equal(1.0))
+		Expect(value("sentry_client_pending_events", "")).To(Equal(3.0))
+	})
+	It("counts exclusions by matcher", func() {
+		client.CaptureException(context.Canceled, nil, nil)
+		Expect(value("sentry_client_excluded_total", "sentry.ExcludeErrorIs")).To(Equal(1.0))
+	})
+	It("counts excluded events by matcher", func() {
+		scope := libsentry.NewScope()
+		scope.SetLevel(libsentry.LevelDebug)
+		Expect(client.CaptureMessage("banana", nil, scope)).To(BeNil())
+		Expect(value("sentry_client_excluded_total", "sentry.ExcludeEventLevel")).To(Equal(1.0))
+		Expect(value("sentry_client_event_id_nil_total", sentry.CaptureKindMessage)).To(Equal(0.0))
+		Expect(value("sentry_client_pending_events", "")).To(Equal(0.0))
+	})
+	It("names method values without suffix", func() {
+		registry = prometheus.NewRegistry()
+		metrics, err := sentry.NewMetrics(ctx, registry)
+		Expect(err).To(BeNil())
+		client, err = sentry.NewClientWithOptions(ctx, clientOptions, func(options *sentry.Options) {
+			options.Metrics = metrics
+			options.ExcludeErrors = sentry.ExcludeErrors{
+				sentry.ExcludeErrors{sentry.ExcludeErrorIs(context.Canceled)}.IsExcluded,
+			}
+		})
+		Expect(err).To(BeNil())
+		client.CaptureException(context.Canceled, nil, nil)
+		Expect(value("sentry_client_excluded_total", "sentry.ExcludeErrors.IsExcluded")).To(Equal(1.0))
+	})
+	Context("BeforeSend", func() {
+		BeforeEach(func() {
+			clientOptions.BeforeSend = func(
+				event *libsentry.Event,
+				hint *libsentry.EventHint,
+			) *libsentry.Event {
+				return nil
+			}
+		})
+		It("counts captures without event id", func() {
+			Expect(client.CaptureMessage("banana", nil, nil)).To(BeNil())
+			Expect(value("sentry_client_event_id_nil_total", sentry.CaptureKindMessage)).To(Equal(1.0))
+			Expect(value("sentry_client_pending_events", "")).To(Equal(0.0))
+		})
+	})
+	It("counts captures dropped after close", func() {
+		Expect(client.Close()).To(BeNil())
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(value("sentry_client_dropped_total", "closed")).To(Equal(1.0))
+	})
+	It("records flush durations", func() {
+		Expect(client.Flush(time.Second)).To(BeTrue())
+		Expect(value("sentry_client_flush_duration_seconds", "delivered")).To(Equal(1.0))
+	})
+	Context("sentry accepts", func() {
+		var server *httptest.Server
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusOK)
+			}))
+			clientOptions.Dsn = strings.Replace(server.URL, "http://", "http://public@", 1) + "/1"
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("removes delivered events from pending events without flush", func() {
+			client.CaptureException(stderrors.New("banana"), nil, nil)
+			client.CaptureMessage("banana", nil, nil)
+			Eventually(func() float64 {
+				return value("sentry_client_pending_events", "")
+			}).Should(Equal(0.0))
+		})
+	})
+	Context("sentry fails", func() {
+		var server *httptest.Server
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}))
+			clientOptions.Dsn = strings.Replace(server.URL, "http://", "http://public@", 1) + "/1"
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("counts transport errors by status", func() {
+			client.CaptureException(stderrors.New("banana"), nil, nil)
+			client.Flush(time.Second)
+			Eventually(func() float64 {
+				return value("sentry_client_transport_errors_total", "500")
+			}).Should(Equal(1.0))
+		})
+	})
+	It("shares the collectors of multiple clients", func() {
+		_, err := sentry.NewMetrics(ctx, registry)
+		Expect(err).To(BeNil())
+	})
+	It("sums the pending events of multiple clients", func() {
+		newFlushClient := func() sentry.Client {
+			metrics, err := sentry.NewMetrics(ctx, registry)
+			Expect(err).To(BeNil())
+			transport := &flushTransport{}
+			transport.delivered.Store(true)
+			client, err := sentry.NewClientWithOptions(
+				ctx,
+				libsentry.ClientOptions{Transport: transport},
+				func(options *sentry.Options) {
+					options.Metrics = metrics
+				},
+			)
+			Expect(err).To(BeNil())
+			return client
+		}
+		first := newFlushClient()
+		second := newFlushClient()
+		first.CaptureMessage("banana", nil, nil)
+		first.CaptureMessage("banana", nil, nil)
+		second.CaptureMessage("banana", nil, nil)
+		Expect(value("sentry_client_pending_events", "")).To(Equal(3.0))
+
+		Expect(second.Flush(time.Second)).To(BeTrue())
+		Expect(value("sentry_client_pending_events", "")).To(Equal(2.0))
+		Expect(first.Close()).To(BeNil())
+		Expect(value("sentry_client_pending_events", "")).To(Equal(0.0))
+	})
+})
@@ -0,0 +1,141 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+	stdtime "time"
+
+	"github.com/bborbe/run"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// MonitorContext is the event context linking a reported error to the check-in of its
+// monitor.
+const MonitorContext = "monitor"
+
+// MonitoredRunnableOptions configures the monitor upserted by NewMonitoredRunnable.
+type MonitoredRunnableOptions struct {
+	// CheckInMargin is the number of minutes after the expected time a check-in is not
+	// considered missed.
+	CheckInMargin int64
+	// MaxRuntime is the number of minutes a run may be in progress before it is
+	// considered failed.
+	MaxRuntime int64
+	// Timezone is the tz database name of the timezone of the schedule.
+	Timezone string
+	// FailureIssueThreshold is the number of consecutive failed check-ins that create an issue.
+	FailureIssueThreshold int64
+	// RecoveryThreshold is the number of consecutive ok check-ins that resolve an issue.
+	RecoveryThreshold int64
+	// FlushTimeout is the maximum duration to wait for the events of a panic to be sent to
+	// Sentry before the panic continues.
+	FlushTimeout stdtime.Duration
+	// Now returns the current time. Defaults to time.Now and can be replaced in tests.
+	Now func() stdtime.Time
+}
+
+// NewMonitoredRunnable creates a run.Func that reports each execution of the given action
+// to the Sentry Crons monitor with the given slug. An in_progress check-in is sent before
+// the action runs, an ok or error check-in with the duration after it. The monitor is
+// upserted with the given schedule. An error of the action is reported via
+// CaptureException with the check-in in context MonitorContext and returned. A panic of
+// the action is reported like NewRecoverAndReport does with the check-in in context
+// MonitorContext, followed by an error check-in, and re-raised after the events are flushed.
+//
+//	sentry.NewMonitoredRunnable(
+//	    sentryClient,
+//	    "daily-report",
+//	    sentry.CrontabSchedule("0 6 * * *"),
+//	    reportRunnable,
+//	)
+func NewMonitoredRunnable(
+	sentryClient Client,
+	monitorSlug string,
+	schedule sentry.MonitorSchedule,
+	action run.Runnable,
+	optionFns ...func(options *MonitoredRunnableOptions),
+) run.Func {
+	options := MonitoredRunnableOptions{
+		FlushTimeout: 2 * stdtime.Second,
+		Now:          stdtime.Now,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	monitorConfig := &sentry.MonitorConfig{
+		Schedule:              schedule,
+		CheckInMargin:         options.CheckInMargin,
+		MaxRuntime:            options.MaxRuntime,
+		Timezone:              options.Timezone,
+		FailureIssueThreshold: options.FailureIssueThreshold,
+		RecoveryThreshold:     options.RecoveryThreshold,
+	}
+	return func(ctx context.Context) (err error) {
+		start := options.Now()
+		checkIn := &sentry.CheckIn{
+			MonitorSlug: monitorSlug,
+			Status:      sentry.CheckInStatusInProgress,
+		}
+		if checkInID := sentryClient.CaptureCheckIn(checkIn, monitorConfig, nil); checkInID != nil {
+			checkIn.ID = *checkInID
+		}
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// the action panics, report the panic and the run as failed and continue panicking
+			recovered := recover()
+			if recovered != nil {
+				_ = reportPanic(
+					ctx,
+					sentryClient,
+					recovered,
+					sentry.NewStacktrace(),
+					monitorScope(monitorSlug, checkIn.ID),
+				)
+			}
+			checkIn.Status = sentry.CheckInStatusError
+			checkIn.Duration = options.Now().Sub(start)
+			sentryClient.CaptureCheckIn(checkIn, monitorConfig, nil)
+			if recovered == nil {
+				// runtime.Goexit, nothing to re-raise
+				return
+			}
+			if !sentryClient.Flush(options.FlushTimeout) {
+				glog.Warningf("flush sentry events after panic failed")
+			}
+			panic(recovered)
+		}()
+		err = action.Run(ctx)
+		completed = true
+
+		checkIn.Duration = options.Now().Sub(start)
+		checkIn.Status = sentry.CheckInStatusOK
+		if err != nil {
+			checkIn.Status = sentry.CheckInStatusError
+			glog.V(2).Infof("monitored run of %s failed: %v", monitorSlug, err)
+			sentryClient.CaptureException(
+				err,
+				&sentry.EventHint{Context: ctx},
+				monitorScope(monitorSlug, checkIn.ID),
+			)
+		}
+		sentryClient.CaptureCheckIn(checkIn, monitorConfig, nil)
+		return err
+	}
+}
+
+// monitorScope returns a scope linking an event to the check-in of the monitor.
+func monitorScope(monitorSlug string, checkInID sentry.EventID) *sentry.Scope {
+	scope := sentry.NewScope()
+	scope.SetContext(MonitorContext, sentry.Context{
+		"slug":        monitorSlug,
+		"check_in_id": string(checkInID),
+	})
+	return scope
+}
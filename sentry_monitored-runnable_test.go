@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/bborbe/run"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/mocks"
+)
+
+var _ = Describe("MonitoredRunnable", func() {
+	var ctx context.Context
+	var sentryClient *mocks.SentryClient
+	var now time.Time
+	var actionErr error
+	var runnable run.Func
+	var checkInID libsentry.EventID
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2026, 1, 2, 6, 0, 0, 0, time.UTC)
+		actionErr = nil
+		checkInID = "check-in-1"
+		sentryClient = &mocks.SentryClient{}
+		sentryClient.CaptureCheckInReturns(&checkInID)
+		runnable = sentry.NewMonitoredRunnable(
+			sentryClient,
+			"daily-report",
+			libsentry.CrontabSchedule("0 6 * * *"),
+			run.Func(func(ctx context.Context) error {
+				now = now.Add(3 * time.Second)
+				return actionErr
+			}),
+			func(options *sentry.MonitoredRunnableOptions) {
+				options.MaxRuntime = 10
+				options.Now = func() time.Time { return now }
+			},
+		)
+	})
+	checkInStatus := func(i int) (libsentry.CheckInStatus, time.Duration) {
+		checkIn, _, _ := sentryClient.CaptureCheckInArgsForCall(i)
+		return checkIn.Status, checkIn.Duration
+	}
+	Context("success", func() {
+		var err error
+		BeforeEach(func() {
+			var statuses []libsentry.CheckInStatus
+			sentryClient.CaptureCheckInCalls(func(
+				checkIn *libsentry.CheckIn,
+				monitorConfig *libsentry.MonitorConfig,
+				scope libsentry.EventModifier,
+			) *libsentry.EventID {
+				statuses = append(statuses, checkIn.Status)
+				Expect(checkIn.MonitorSlug).To(Equal("daily-report"))
+				Expect(monitorConfig.MaxRuntime).To(Equal(int64(10)))
+				Expect(monitorConfig.Schedule).To(Equal(libsentry.CrontabSchedule("0 6 * * *")))
+				return &checkInID
+			})
+			err = runnable.Run(ctx)
+			Expect(statuses).To(Equal([]libsentry.CheckInStatus{
+				libsentry.CheckInStatusInProgress,
+				libsentry.CheckInStatusOK,
+			}))
+		})
+		It("returns no error", func() {
+			Expect(err).To(BeNil())
+		})
+		It("sends the duration", func() {
+			_, duration := checkInStatus(1)
+			Expect(duration).To(Equal(3 * time.Second))
+		})
+		It("reuses the check-in id", func() {
+			checkIn, _, _ := sentryClient.CaptureCheckInArgsForCall(1)
+			Expect(checkIn.ID).To(Equal(checkInID))
+		})
+		It("captures no exception", func() {
+			Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(0))
+		})
+	})
+	Context("failure", func() {
+		var err error
+		BeforeEach(func() {
+			actionErr = stderrors.New("banana")
+			err = runnable.Run(ctx)
+		})
+		It("returns the error", func() {
+			Expect(err).To(MatchError("banana"))
+		})
+		It("sends an error check-in", func() {
+			Expect(sentryClient.CaptureCheckInCallCount()).To(Equal(2))
+			status, _ := checkInStatus(1)
+			Expect(status).To(Equal(libsentry.CheckInStatusError))
+		})
+		It("captures the error linked to the check-in", func() {
+			Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(1))
+			capturedErr, _, scope := sentryClient.CaptureExceptionArgsForCall(0)
+			Expect(capturedErr).To(MatchError("banana"))
+			event := scope.ApplyToEvent(libsentry.NewEvent(), nil, nil)
+			Expect(event.Contexts[sentry.MonitorContext]).To(HaveKeyWithValue("slug", "daily-report"))
+			Expect(event.Contexts[sentry.MonitorContext]).To(
+				HaveKeyWithValue("check_in_id", "check-in-1"),
+			)
+		})
+	})
+	Context("panic", func() {
+		It("sends an error check-in and repanics", func() {
+			runnable = sentry.NewMonitoredRunnable(
+				sentryClient,
+				"daily-report",
+				libsentry.CrontabSchedule("0 6 * * *"),
+				run.Func(func(ctx context.Context) error {
+					panic("boom")
+				}),
+			)
+			Expect(func() { _ = runnable.Run(ctx) }).To(PanicWith("boom"))
+			Expect(sentryClient.CaptureCheckInCallCount()).To(Equal(2))
+			status, _ := checkInStatus(1)
+			Expect(status).To(Equal(libsentry.CheckInStatusError))
+		})
+		It("captures the panic linked to the check-in and flushes", func() {
+			runnable = sentry.NewMonitoredRunnable(
+				sentryClient,
+				"daily-report",
+				libsentry.CrontabSchedule("0 6 * * *"),
+				run.Func(func(ctx context.Context) error {
+					panic("boom")
+				}),
+			)
+			Expect(func() { _ = runnable.Run(ctx) }).To(PanicWith("boom"))
+			Expect(sentryClient.CaptureExceptionCallCount()).To(Equal(1))
+			capturedErr, _, scope := sentryClient.CaptureExceptionArgsForCall(0)
+			Expect(capturedErr).To(MatchError(ContainSubstring("panic: boom")))
+			event := scope.ApplyToEvent(libsentry.NewEvent(), nil, nil)
+			Expect(event.Level).To(Equal(libsentry.LevelFatal))
+			Expect(event.Contexts[sentry.MonitorContext]).To(HaveKeyWithValue("slug", "daily-report"))
+			Expect(sentryClient.FlushCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// maxPendingEvents is the number of pending events tracked by id. Events above it are only
+// counted until the next successful Flush.
+const maxPendingEvents = 10000
+
+func newPendingEvents(metrics Metrics) *pendingEvents {
+	return &pendingEvents{
+		metrics: metrics,
+		ids:     make(map[sentry.EventID]struct{}),
+	}
+}
+
+// pendingEvents tracks the events passed to the transport until their delivery is
+// confirmed by a successful request to Sentry or a successful Flush.
+type pendingEvents struct {
+	metrics Metrics
+
+	mux sync.Mutex
+	ids map[sentry.EventID]struct{}
+	// untracked is the number of pending events above maxPendingEvents
+	untracked int
+	// reported is the number of pending events added to the metrics
+	reported int
+}
+
+func (p *pendingEvents) add(id sentry.EventID) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if _, ok := p.ids[id]; ok {
+		return
+	}
+	if len(p.ids) >= maxPendingEvents {
+		p.untracked++
+	} else {
+		p.ids[id] = struct{}{}
+	}
+	p.reportLocked()
+}
+
+func (p *pendingEvents) remove(id sentry.EventID) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if _, ok := p.ids[id]; !ok {
+		return
+	}
+	delete(p.ids, id)
+	p.reportLocked()
+}
+
+// snapshot returns the currently pending events, which are removed by removeSnapshot after
+// a successful Flush. Events added during the Flush stay pending.
+func (p *pendingEvents) snapshot() pendingSnapshot {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	ids := make([]sentry.EventID, 0, len(p.ids))
+	for id := range p.ids {
+		ids = append(ids, id)
+	}
+	return pendingSnapshot{ids: ids, untracked: p.untracked}
+}
+
+func (p *pendingEvents) removeSnapshot(snapshot pendingSnapshot) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for _, id := range snapshot.ids {
+		delete(p.ids, id)
+	}
+	p.untracked = max(0, p.untracked-snapshot.untracked)
+	p.reportLocked()
+}
+
+func (p *pendingEvents) count() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.countLocked()
+}
+
+// clear forgets all pending events, e.g. because they are dropped after Close.
+func (p *pendingEvents) clear() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	clear(p.ids)
+	p.untracked = 0
+	p.reportLocked()
+}
+
+func (p *pendingEvents) countLocked() int {
+	return len(p.ids) + p.untracked
+}
+
+// reportLocked adds the change since the last report to the metrics, which can be shared
+// with other clients.
+func (p *pendingEvents) reportLocked() {
+	count := p.countLocked()
+	if count == p.reported {
+		return
+	}
+	p.metrics.PendingEventsAdd(int64(count - p.reported))
+	p.reported = count
+}
+
+type pendingSnapshot struct {
+	ids       []sentry.EventID
+	untracked int
+}
+
+// newPendingRoundTripper creates an http.RoundTripper that confirms the delivery of the
+// event of an envelope request once Sentry accepted or finally rejected it. Failed
+// requests keep the event pending.
+func newPendingRoundTripper(
+	roundTripper http.RoundTripper,
+	pending *pendingEvents,
+) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		eventID := envelopeEventID(req)
+		resp, err := roundTripper.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if eventID != "" && isDelivered(resp.StatusCode) {
+			pending.remove(eventID)
+		}
+		return resp, nil
+	})
+}
+
+// isDelivered returns true if Sentry accepted the request or rejected it in a way no
+// transport retries.
+func isDelivered(statusCode int) bool {
+	return statusCode < http.StatusInternalServerError &&
+		statusCode != http.StatusTooManyRequests
+}
+
+// envelopeEventID returns the event id of the envelope header of the request body or an
+// empty id if the body can not be read without consuming it.
+func envelopeEventID(req *http.Request) sentry.EventID {
+	if req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	buf := make([]byte, 4096)
+	n, _ := io.ReadFull(body, buf)
+	line, _, _ := bytes.Cut(buf[:n], []byte("\n"))
+	var header struct {
+		EventID sentry.EventID `json:"event_id"`
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return ""
+	}
+	return header.EventID
+}
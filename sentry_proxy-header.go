@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	stdtime "time"
+
+	"github.com/bborbe/errors"
+	"github.com/golang/glog"
+)
+
+// ProxyHeaderFunc returns headers added to the requests sent to a relay, see
+// ProxyRoundTripperOptions.HeaderFuncs.
+type ProxyHeaderFunc func(ctx context.Context) (http.Header, error)
+
+// ProxyHeaderFromFile returns the header with the given name and the content of the given
+// file, prefixed by valuePrefix. The file is read again after it changed, so rotated
+// secrets, e.g. mounted by Kubernetes, are picked up without restart.
+//
+//	sentry.ProxyHeaderFromFile("Authorization", "Bearer ", "/var/run/secrets/relay-token")
+func ProxyHeaderFromFile(name string, valuePrefix string, path string) ProxyHeaderFunc {
+	var mux sync.Mutex
+	var modTime stdtime.Time
+	var size int64
+	var value string
+	return func(ctx context.Context) (http.Header, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "stat %s failed", path)
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		if value == "" || !info.ModTime().Equal(modTime) || info.Size() != size {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, errors.Wrapf(ctx, err, "read %s failed", path)
+			}
+			glog.V(3).Infof("read proxy header %s from %s", name, path)
+			value = strings.TrimSpace(string(content))
+			modTime = info.ModTime()
+			size = info.Size()
+		}
+		header := make(http.Header)
+		header.Set(name, valuePrefix+value)
+		return header, nil
+	}
+}
@@ -1,44 +1,315 @@
-// Copyright (c) 2024-2025 Benjamin Borbe All rights reserved.
+// Copyright (c) 2024-2026 Benjamin Borbe All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
 package sentry
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	stdtime "time"
 
 	"github.com/bborbe/errors"
 	"github.com/golang/glog"
 )
 
+// DefaultProxyCooldown is the duration a relay is skipped after it failed.
+const DefaultProxyCooldown = 30 * stdtime.Second
+
+// ProxyRoundTripperOptions configures the RoundTripper created by
+// NewProxyRoundTripperWithOptions.
+type ProxyRoundTripperOptions struct {
+	// URLs are the relays tried in order. The path of a URL is prepended to the path of the
+	// requests, e.g. http://ingress/sentry-relay sends to /sentry-relay/api/1/envelope/.
+	URLs []string
+	// Cooldown is the duration a failed relay is skipped. Defaults to DefaultProxyCooldown.
+	// Without DSNFallback relays in cooldown are still tried if no other relay is left.
+	Cooldown stdtime.Duration
+	// DSNFallback sends a request to the host of the original DSN if all relays failed or
+	// are in cooldown. Defaults to false, which returns the result of the last relay, so
+	// events never bypass the relays.
+	DSNFallback bool
+	// Headers are added to all requests sent to a relay.
+	Headers http.Header
+	// HeaderFuncs return headers added to all requests sent to a relay, e.g. tokens of
+	// ProxyHeaderFromFile. A relay whose headers fail is skipped like a failed relay.
+	HeaderFuncs []ProxyHeaderFunc
+	// ForwardedHost sets header X-Forwarded-Host of requests sent to a relay to the host of
+	// the original DSN. Defaults to true.
+	ForwardedHost bool
+	// Now returns the current time. Defaults to time.Now and can be replaced in tests.
+	Now func() stdtime.Time
+}
+
 // NewProxyRoundTripper creates an HTTP RoundTripper that proxies Sentry requests to a
 // different host without modifying the alert content. This is useful for routing Sentry
 // traffic through a proxy server or testing with a local Sentry instance.
+//
+// The given relay URLs are tried in order. A relay failing with a connection error or a
+// gateway status (502, 503, 504) is skipped for DefaultProxyCooldown and the request is sent
+// to the next one. Other statuses are responses of Sentry passed through by the relay and
+// are returned. If all relays fail the result of the last relay is returned; requests are
+// never sent to the host of the original DSN, see ProxyRoundTripperOptions.DSNFallback.
+// Only scheme and host of the URLs are used, the path is ignored and no X-Forwarded-Host
+// is set. Invalid URLs are returned as error of every request. Use
+// NewProxyRoundTripperWithOptions for path prefixes, headers and validation at construction.
+//
+//	sentry.NewProxyRoundTripper(http.DefaultTransport, "http://relay-0:3000", "http://relay-1:3000")
 func NewProxyRoundTripper(
 	roundtripper http.RoundTripper,
-	url string,
+	urls ...string,
 ) http.RoundTripper {
+	result, err := newProxyRoundTripper(
+		context.Background(),
+		roundtripper,
+		false,
+		func(options *ProxyRoundTripperOptions) {
+			options.URLs = urls
+			options.ForwardedHost = false
+		},
+	)
+	if err != nil {
+		glog.Warningf("create proxy round tripper failed: %v", err)
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, err
+		})
+	}
+	return result
+}
+
+// NewProxyRoundTripperWithOptions creates a RoundTripper like NewProxyRoundTripper,
+// configured by the given option functions. It returns an error if a URL is invalid.
+//
+//	roundTripper, err := sentry.NewProxyRoundTripperWithOptions(
+//	    ctx,
+//	    http.DefaultTransport,
+//	    func(options *sentry.ProxyRoundTripperOptions) {
+//	        options.URLs = []string{"https://ingress/sentry-relay"}
+//	        options.HeaderFuncs = []sentry.ProxyHeaderFunc{
+//	            sentry.ProxyHeaderFromFile("Authorization", "Bearer ", tokenPath),
+//	        }
+//	    },
+//	)
+func NewProxyRoundTripperWithOptions(
+	ctx context.Context,
+	roundtripper http.RoundTripper,
+	optionFns ...func(options *ProxyRoundTripperOptions),
+) (http.RoundTripper, error) {
+	return newProxyRoundTripper(ctx, roundtripper, true, optionFns...)
+}
+
+// newProxyRoundTripper creates the RoundTripper. The path of the URLs is only prepended
+// with pathPrefix, NewProxyRoundTripper ignores it.
+func newProxyRoundTripper(
+	ctx context.Context,
+	roundtripper http.RoundTripper,
+	pathPrefix bool,
+	optionFns ...func(options *ProxyRoundTripperOptions),
+) (*roundTripper, error) {
+	options := ProxyRoundTripperOptions{
+		Cooldown:      DefaultProxyCooldown,
+		ForwardedHost: true,
+		Now:           stdtime.Now,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	upstreams := make([]*proxyUpstream, 0, len(options.URLs))
+	for _, rawURL := range options.URLs {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "parse url %s failed", rawURL)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, errors.Errorf(ctx, "url %s has no scheme or host", rawURL)
+		}
+		upstreams = append(upstreams, &proxyUpstream{url: u})
+	}
 	return &roundTripper{
 		roundtripper: roundtripper,
-		url:          url,
-	}
+		upstreams:    upstreams,
+		options:      options,
+		pathPrefix:   pathPrefix,
+	}, nil
+}
+
+// proxyUpstream is a relay with its health.
+type proxyUpstream struct {
+	url *url.URL
+	// failures is the number of consecutive failed requests
+	failures int
+	// openUntil is the time until the relay is skipped
+	openUntil stdtime.Time
 }
 
 type roundTripper struct {
 	roundtripper http.RoundTripper
-	url          string
+	upstreams    []*proxyUpstream
+	options      ProxyRoundTripperOptions
+	pathPrefix   bool
+
+	mux sync.Mutex
 }
 
 func (r *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	glog.V(4).Infof("original request to %s", req.URL.String())
-	u, err := url.Parse(r.url)
+	body, err := readRequestBody(req)
 	if err != nil {
-		return nil, errors.Wrapf(req.Context(), err, "parse url %s failed", r.url)
+		return nil, errors.Wrapf(req.Context(), err, "read request body failed")
+	}
+	if len(r.upstreams) == 0 {
+		return r.roundtripper.RoundTrip(withRequestBody(req, body))
+	}
+	upstreams := r.available()
+	for i, upstream := range upstreams {
+		// without DSN fallback the result of the last relay is returned
+		last := i == len(upstreams)-1 && !r.options.DSNFallback
+		proxyReq, err := r.proxyRequest(req, body, upstream.url)
+		if err != nil {
+			r.markFailed(upstream, nil, err)
+			if last {
+				return nil, err
+			}
+			continue
+		}
+		glog.V(4).Infof("send request to %s", proxyReq.URL.String())
+		resp, err := r.roundtripper.RoundTrip(proxyReq)
+		if req.Context().Err() != nil {
+			// the caller gave up, the health of the relay is unknown
+			return resp, err
+		}
+		if !isRelayFailure(resp, err) {
+			r.markHealthy(upstream)
+			return resp, err
+		}
+		r.markFailed(upstream, resp, err)
+		if last {
+			return resp, err
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+	}
+	glog.V(2).Infof("no sentry relay available => send request to %s", req.URL.Host)
+	return r.roundtripper.RoundTrip(withRequestBody(req, body))
+}
+
+// proxyRequest returns a copy of the request addressed to the given relay.
+func (r *roundTripper) proxyRequest(
+	req *http.Request,
+	body []byte,
+	u *url.URL,
+) (*http.Request, error) {
+	proxyReq := withRequestBody(req, body)
+	proxyReq.URL.Host = u.Host
+	proxyReq.URL.Scheme = u.Scheme
+	proxyReq.Host = u.Host
+	if prefix := strings.TrimSuffix(u.Path, "/"); r.pathPrefix && prefix != "" {
+		proxyReq.URL.Path = prefix + req.URL.Path
+		if req.URL.RawPath != "" {
+			proxyReq.URL.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + req.URL.RawPath
+		}
+	}
+	if r.options.ForwardedHost {
+		proxyReq.Header.Set("X-Forwarded-Host", req.URL.Host)
+	}
+	for name, values := range r.options.Headers {
+		proxyReq.Header[name] = values
+	}
+	for _, headerFunc := range r.options.HeaderFuncs {
+		header, err := headerFunc(req.Context())
+		if err != nil {
+			return nil, errors.Wrapf(req.Context(), err, "get proxy header failed")
+		}
+		for name, values := range header {
+			proxyReq.Header[name] = values
+		}
+	}
+	return proxyReq, nil
+}
+
+// available returns the relays not in cooldown in the configured order. Without DSN
+// fallback all relays are returned if all are in cooldown.
+func (r *roundTripper) available() []*proxyUpstream {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	now := r.options.Now()
+	result := make([]*proxyUpstream, 0, len(r.upstreams))
+	for _, upstream := range r.upstreams {
+		if now.Before(upstream.openUntil) {
+			continue
+		}
+		result = append(result, upstream)
+	}
+	if len(result) == 0 && !r.options.DSNFallback {
+		return slices.Clone(r.upstreams)
+	}
+	return result
+}
+
+func (r *roundTripper) markHealthy(upstream *proxyUpstream) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if upstream.failures > 0 {
+		glog.V(2).Infof("sentry relay %s recovered after %d failures", upstream.url, upstream.failures)
+	}
+	upstream.failures = 0
+	upstream.openUntil = stdtime.Time{}
+}
+
+func (r *roundTripper) markFailed(upstream *proxyUpstream, resp *http.Response, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	upstream.failures++
+	upstream.openUntil = r.options.Now().Add(r.options.Cooldown)
+	if err != nil {
+		glog.Warningf("sentry relay %s failed: %v => skip for %v",
+			upstream.url, err, r.options.Cooldown)
+		return
+	}
+	glog.Warningf("sentry relay %s returned status %d => skip for %v",
+		upstream.url, resp.StatusCode, r.options.Cooldown)
+}
+
+// isRelayFailure returns true if the relay could not handle the request. Other 5xx statuses
+// are responses of Sentry passed through by the relay, another relay would get the same.
+func isRelayFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// readRequestBody reads the body, so the request can be sent more than once.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// withRequestBody returns a copy of the request with the given body.
+func withRequestBody(req *http.Request, body []byte) *http.Request {
+	result := req.Clone(req.Context())
+	if body == nil {
+		return result
+	}
+	result.Body = io.NopCloser(bytes.NewReader(body))
+	result.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
 	}
-	req.URL.Host = u.Host
-	req.URL.Scheme = u.Scheme
-	req.Host = u.Host
-	glog.V(4).Infof("send request to %s", req.URL.String())
-	return r.roundtripper.RoundTrip(req)
+	result.ContentLength = int64(len(body))
+	return result
 }
@@ -0,0 +1,304 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+)
+
+// proxyServer is an httptest server recording the bodies it received.
+type proxyServer struct {
+	*httptest.Server
+	status atomic.Int64
+
+	mux     sync.Mutex
+	bodies  []string
+	paths   []string
+	headers []http.Header
+}
+
+func newProxyServer(status int) *proxyServer {
+	s := &proxyServer{}
+	s.status.Store(int64(status))
+	s.Server = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		s.mux.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.paths = append(s.paths, req.URL.Path)
+		s.headers = append(s.headers, req.Header.Clone())
+		s.mux.Unlock()
+		resp.WriteHeader(int(s.status.Load()))
+	}))
+	return s
+}
+
+func (s *proxyServer) Bodies() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func (s *proxyServer) Paths() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]string(nil), s.paths...)
+}
+
+func (s *proxyServer) LastHeader() http.Header {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if len(s.headers) == 0 {
+		return nil
+	}
+	return s.headers[len(s.headers)-1]
+}
+
+var _ = Describe("ProxyRoundTripper", func() {
+	var ctx context.Context
+	var origin *proxyServer
+	var relays []*proxyServer
+	BeforeEach(func() {
+		ctx = context.Background()
+		origin = newProxyServer(http.StatusOK)
+		relays = []*proxyServer{
+			newProxyServer(http.StatusOK),
+			newProxyServer(http.StatusOK),
+		}
+	})
+	AfterEach(func() {
+		origin.Close()
+		for _, relay := range relays {
+			relay.Close()
+		}
+	})
+	send := func(roundTripper http.RoundTripper) int {
+		req, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodPost,
+			origin.URL+"/api/1/envelope/",
+			strings.NewReader("banana"),
+		)
+		Expect(err).To(BeNil())
+		resp, err := roundTripper.RoundTrip(req)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	newRoundTripper := func() http.RoundTripper {
+		return sentry.NewProxyRoundTripper(http.DefaultTransport, relays[0].URL, relays[1].URL)
+	}
+	It("sends to the first relay", func() {
+		Expect(send(newRoundTripper())).To(Equal(http.StatusOK))
+		Expect(relays[0].Bodies()).To(Equal([]string{"banana"}))
+		Expect(relays[1].Bodies()).To(BeEmpty())
+		Expect(origin.Bodies()).To(BeEmpty())
+	})
+	It("fails over on gateway errors", func() {
+		relays[0].status.Store(http.StatusServiceUnavailable)
+		Expect(send(newRoundTripper())).To(Equal(http.StatusOK))
+		Expect(relays[0].Bodies()).To(HaveLen(1))
+		Expect(relays[1].Bodies()).To(Equal([]string{"banana"}))
+	})
+	It("fails over on connection errors", func() {
+		relays[0].Close()
+		Expect(send(newRoundTripper())).To(Equal(http.StatusOK))
+		Expect(relays[1].Bodies()).To(Equal([]string{"banana"}))
+	})
+	It("does not fail over on 500 of sentry passed through by the relay", func() {
+		relays[0].status.Store(http.StatusInternalServerError)
+		Expect(send(newRoundTripper())).To(Equal(http.StatusInternalServerError))
+		Expect(relays[1].Bodies()).To(BeEmpty())
+	})
+	It("does not fail over on 4xx", func() {
+		relays[0].status.Store(http.StatusTooManyRequests)
+		Expect(send(newRoundTripper())).To(Equal(http.StatusTooManyRequests))
+		Expect(relays[1].Bodies()).To(BeEmpty())
+	})
+	It("skips failed relays during cooldown", func() {
+		relays[0].status.Store(http.StatusBadGateway)
+		roundTripper := newRoundTripper()
+		Expect(send(roundTripper)).To(Equal(http.StatusOK))
+		Expect(send(roundTripper)).To(Equal(http.StatusOK))
+		Expect(relays[0].Bodies()).To(HaveLen(1))
+		Expect(relays[1].Bodies()).To(HaveLen(2))
+	})
+	It("returns the result of the last relay instead of the original host", func() {
+		relays[0].Close()
+		relays[1].status.Store(http.StatusServiceUnavailable)
+		Expect(send(newRoundTripper())).To(Equal(http.StatusServiceUnavailable))
+		Expect(origin.Bodies()).To(BeEmpty())
+	})
+	It("keeps sending to a single relay in cooldown", func() {
+		relays[0].status.Store(http.StatusBadGateway)
+		roundTripper := sentry.NewProxyRoundTripper(http.DefaultTransport, relays[0].URL)
+		Expect(send(roundTripper)).To(Equal(http.StatusBadGateway))
+		Expect(send(roundTripper)).To(Equal(http.StatusBadGateway))
+		Expect(relays[0].Bodies()).To(HaveLen(2))
+		Expect(origin.Bodies()).To(BeEmpty())
+	})
+	It("ignores the path of relay urls and sets no X-Forwarded-Host", func() {
+		roundTripper := sentry.NewProxyRoundTripper(http.DefaultTransport, relays[0].URL+"/sentry-relay")
+		Expect(send(roundTripper)).To(Equal(http.StatusOK))
+		Expect(relays[0].Paths()).To(Equal([]string{"/api/1/envelope/"}))
+		Expect(relays[0].LastHeader().Get("X-Forwarded-Host")).To(BeEmpty())
+	})
+	It("sends to the original host without relays", func() {
+		Expect(send(sentry.NewProxyRoundTripper(http.DefaultTransport))).To(Equal(http.StatusOK))
+		Expect(origin.Bodies()).To(Equal([]string{"banana"}))
+	})
+	It("returns the error of invalid urls", func() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin.URL, nil)
+		Expect(err).To(BeNil())
+		_, err = sentry.NewProxyRoundTripper(http.DefaultTransport, "relay").RoundTrip(req)
+		Expect(err).To(HaveOccurred())
+		Expect(origin.Bodies()).To(BeEmpty())
+	})
+	Context("WithOptions", func() {
+		var now time.Time
+		var optionFns []func(options *sentry.ProxyRoundTripperOptions)
+		BeforeEach(func() {
+			now = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			optionFns = []func(options *sentry.ProxyRoundTripperOptions){
+				func(options *sentry.ProxyRoundTripperOptions) {
+					options.URLs = []string{relays[0].URL, relays[1].URL}
+					options.Now = func() time.Time { return now }
+				},
+			}
+		})
+		newRoundTripperWithOptions := func() http.RoundTripper {
+			roundTripper, err := sentry.NewProxyRoundTripperWithOptions(
+				ctx,
+				http.DefaultTransport,
+				optionFns...,
+			)
+			Expect(err).To(BeNil())
+			return roundTripper
+		}
+		It("rejects invalid urls", func() {
+			_, err := sentry.NewProxyRoundTripperWithOptions(
+				ctx,
+				http.DefaultTransport,
+				func(options *sentry.ProxyRoundTripperOptions) {
+					options.URLs = []string{"relay:3000/path"}
+				},
+			)
+			Expect(err).To(HaveOccurred())
+		})
+		It("prepends the path of the relay url", func() {
+			optionFns = append(optionFns, func(options *sentry.ProxyRoundTripperOptions) {
+				options.URLs = []string{relays[0].URL + "/sentry-relay/"}
+			})
+			Expect(send(newRoundTripperWithOptions())).To(Equal(http.StatusOK))
+			Expect(relays[0].Paths()).To(Equal([]string{"/sentry-relay/api/1/envelope/"}))
+		})
+		It("sets X-Forwarded-Host", func() {
+			Expect(send(newRoundTripperWithOptions())).To(Equal(http.StatusOK))
+			Expect(relays[0].LastHeader().Get("X-Forwarded-Host")).To(Equal(origin.Listener.Addr().String()))
+		})
+		It("adds static headers", func() {
+			optionFns = append(optionFns, func(options *sentry.ProxyRoundTripperOptions) {
+				options.Headers = http.Header{"X-Ingress-Key": []string{"secret"}}
+			})
+			Expect(send(newRoundTripperWithOptions())).To(Equal(http.StatusOK))
+			Expect(relays[0].LastHeader().Get("X-Ingress-Key")).To(Equal("secret"))
+		})
+		It("adds headers from a rotated file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "token")
+			Expect(os.WriteFile(path, []byte("token-1\n"), 0600)).To(Succeed())
+			optionFns = append(optionFns, func(options *sentry.ProxyRoundTripperOptions) {
+				options.HeaderFuncs = []sentry.ProxyHeaderFunc{
+					sentry.ProxyHeaderFromFile("Authorization", "Bearer ", path),
+				}
+			})
+			roundTripper := newRoundTripperWithOptions()
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(relays[0].LastHeader().Get("Authorization")).To(Equal("Bearer token-1"))
+
+			Expect(os.WriteFile(path, []byte("token-22\n"), 0600)).To(Succeed())
+			later := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(path, later, later)).To(Succeed())
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(relays[0].LastHeader().Get("Authorization")).To(Equal("Bearer token-22"))
+		})
+		It("falls back to the original host with DSNFallback", func() {
+			optionFns = append(optionFns, func(options *sentry.ProxyRoundTripperOptions) {
+				options.DSNFallback = true
+			})
+			relays[0].status.Store(http.StatusBadGateway)
+			relays[1].Close()
+			roundTripper := newRoundTripperWithOptions()
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(relays[0].Bodies()).To(HaveLen(1))
+			Expect(origin.Bodies()).To(Equal([]string{"banana", "banana"}))
+		})
+		It("skips relays whose headers fail", func() {
+			optionFns = append(optionFns, func(options *sentry.ProxyRoundTripperOptions) {
+				options.DSNFallback = true
+				options.HeaderFuncs = []sentry.ProxyHeaderFunc{
+					sentry.ProxyHeaderFromFile("Authorization", "Bearer ", "/does/not/exist"),
+				}
+			})
+			Expect(send(newRoundTripperWithOptions())).To(Equal(http.StatusOK))
+			Expect(relays[0].Bodies()).To(BeEmpty())
+			Expect(origin.Bodies()).To(HaveLen(1))
+			Expect(origin.LastHeader().Get("Authorization")).To(BeEmpty())
+		})
+		It("keeps the cooldown of a relay on cancelled requests", func() {
+			relays[0].status.Store(http.StatusBadGateway)
+			relays[1].status.Store(http.StatusBadGateway)
+			roundTripper := newRoundTripperWithOptions()
+			Expect(send(roundTripper)).To(Equal(http.StatusBadGateway))
+
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			req, err := http.NewRequestWithContext(
+				cancelledCtx,
+				http.MethodPost,
+				origin.URL+"/api/1/envelope/",
+				strings.NewReader("banana"),
+			)
+			Expect(err).To(BeNil())
+			_, err = roundTripper.RoundTrip(req)
+			Expect(err).To(MatchError(context.Canceled))
+
+			// both relays are still in cooldown, so the second is tried after the first
+			relays[1].status.Store(http.StatusOK)
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(relays[0].Bodies()).To(HaveLen(2))
+			Expect(relays[1].Bodies()).To(HaveLen(2))
+		})
+		It("retries failed relays after the cooldown", func() {
+			optionFns = append(optionFns, func(options *sentry.ProxyRoundTripperOptions) {
+				options.Cooldown = time.Minute
+			})
+			relays[0].status.Store(http.StatusBadGateway)
+			roundTripper := newRoundTripperWithOptions()
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			relays[0].status.Store(http.StatusOK)
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(relays[0].Bodies()).To(HaveLen(1))
+
+			now = now.Add(time.Minute)
+			Expect(send(roundTripper)).To(Equal(http.StatusOK))
+			Expect(relays[0].Bodies()).To(HaveLen(2))
+			Expect(relays[1].Bodies()).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+	stdtime "time"
+
+	"github.com/bborbe/errors"
+	"github.com/bborbe/run"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// RecoverAndReportOptions configures the behaviour of NewRecoverAndReport.
+type RecoverAndReportOptions struct {
+	// Repanic re-raises the recovered panic after it was reported.
+	// If false the panic is returned as error.
+	Repanic bool
+	// FlushTimeout is the maximum duration to wait for the event to be sent to Sentry.
+	FlushTimeout stdtime.Duration
+}
+
+// NewRecoverAndReport creates a run.Func that executes the given action and recovers
+// from any panic within it. The panic is reported to Sentry as fatal exception with the
+// stacktrace of the panicking goroutine and the data of the context as tags. After the
+// event is flushed the panic is either re-raised or returned as error, depending on
+// RecoverAndReportOptions.Repanic. Errors returned by the action are passed through.
+func NewRecoverAndReport(
+	sentryClient Client,
+	action run.Runnable,
+	optionFns ...func(options *RecoverAndReportOptions),
+) run.Func {
+	options := RecoverAndReportOptions{
+		FlushTimeout: 2 * stdtime.Second,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			err = reportPanic(
+				ctx,
+				sentryClient,
+				recovered,
+				sentry.NewStacktrace(),
+				sentry.NewScope(),
+			)
+			if !sentryClient.Flush(options.FlushTimeout) {
+				glog.Warningf("flush sentry events after panic failed")
+			}
+			if options.Repanic {
+				panic(recovered)
+			}
+		}()
+		return action.Run(ctx)
+	}
+}
+
+func reportPanic(
+	ctx context.Context,
+	sentryClient Client,
+	recovered any,
+	stacktrace *sentry.Stacktrace,
+	scope *sentry.Scope,
+) error {
+	var err error
+	if recoveredErr, ok := recovered.(error); ok {
+		err = errors.Wrap(ctx, recoveredErr, "panic")
+	} else {
+		err = errors.Errorf(ctx, "panic: %v", recovered)
+	}
+	glog.Warningf("run action panics: %v", err)
+	sentryClient.CaptureException(
+		err,
+		&sentry.EventHint{
+			Context:            ctx,
+			Data:               errors.DataFromError(err),
+			OriginalException:  err,
+			RecoveredException: recovered,
+		},
+		EventModifierList{
+			scope,
+			EventModifierFunc(
+				func(event *sentry.Event, hint *sentry.EventHint, client *sentry.Client) *sentry.Event {
+					return applyPanic(event, stacktrace)
+				},
+			),
+		},
+	)
+	return err
+}
+
+// applyPanic marks the event as fatal and unhandled and replaces the stacktrace of the
+// outermost exception with the stacktrace of the panicking goroutine.
+func applyPanic(event *sentry.Event, stacktrace *sentry.Stacktrace) *sentry.Event {
+	event.Level = sentry.LevelFatal
+	if len(event.Exception) == 0 {
+		return event
+	}
+	exception := &event.Exception[len(event.Exception)-1]
+	exception.Stacktrace = stacktrace
+	if exception.Mechanism == nil {
+		exception.Mechanism = &sentry.Mechanism{}
+	}
+	exception.Mechanism.Type = "panic"
+	exception.Mechanism.SetUnhandled()
+	return event
+}
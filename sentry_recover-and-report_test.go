@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/bborbe/errors"
+	runmocks "github.com/bborbe/run/mocks"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("RecoverAndReport", func() {
+	var ctx context.Context
+	var err error
+	var repanic bool
+	var recorder *sentrytest.Recorder
+	var runnable *runmocks.Runnable
+	var run func()
+	BeforeEach(func() {
+		ctx = errors.AddToContext(context.Background(), "job", "import")
+		repanic = false
+		runnable = &runmocks.Runnable{}
+		recorder = sentrytest.NewRecorder()
+		run = func() {
+			recoverAndReport := sentry.NewRecoverAndReport(
+				recorder,
+				runnable,
+				func(options *sentry.RecoverAndReportOptions) {
+					options.Repanic = repanic
+				},
+			)
+			err = recoverAndReport.Run(ctx)
+		}
+	})
+	Context("success", func() {
+		BeforeEach(func() {
+			runnable.RunReturns(nil)
+			run()
+		})
+		It("returns no error", func() {
+			Expect(err).To(BeNil())
+		})
+		It("captures nothing", func() {
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+	})
+	Context("error", func() {
+		BeforeEach(func() {
+			runnable.RunReturns(stderrors.New("banana"))
+			run()
+		})
+		It("returns the error", func() {
+			Expect(err).To(MatchError("banana"))
+		})
+		It("captures nothing", func() {
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+	})
+	Context("panic", func() {
+		BeforeEach(func() {
+			runnable.RunStub = func(ctx context.Context) error {
+				panic("banana")
+			}
+		})
+		Context("without repanic", func() {
+			BeforeEach(func() {
+				run()
+			})
+			It("returns error", func() {
+				Expect(err).To(MatchError("panic: banana"))
+			})
+			It("captures fatal exception", func() {
+				Expect(recorder).To(sentrytest.HaveCapturedException("panic: banana"))
+				event := recorder.Events()[0]
+				Expect(event.Level).To(Equal(libsentry.LevelFatal))
+				Expect(event).To(sentrytest.HaveTag("job", "import"))
+			})
+			It("attaches the stacktrace of the panic", func() {
+				exceptions := recorder.Events()[0].Exception
+				Expect(exceptions).NotTo(BeEmpty())
+				exception := exceptions[len(exceptions)-1]
+				Expect(exception.Mechanism).NotTo(BeNil())
+				Expect(exception.Mechanism.Type).To(Equal("panic"))
+				Expect(*exception.Mechanism.Handled).To(BeFalse())
+				Expect(exception.Stacktrace).NotTo(BeNil())
+				var functions []string
+				for _, frame := range exception.Stacktrace.Frames {
+					functions = append(functions, frame.Function)
+				}
+				Expect(functions).To(ContainElement("(*Runnable).Run"))
+			})
+		})
+		Context("with repanic", func() {
+			BeforeEach(func() {
+				repanic = true
+			})
+			It("panics again after reporting", func() {
+				Expect(run).To(PanicWith("banana"))
+				Expect(recorder).To(sentrytest.HaveCapturedException("panic: banana"))
+			})
+		})
+	})
+	Context("panic with error", func() {
+		BeforeEach(func() {
+			runnable.RunStub = func(ctx context.Context) error {
+				panic(stderrors.New("banana"))
+			}
+			run()
+		})
+		It("returns wrapped error", func() {
+			Expect(err).To(MatchError("panic: banana"))
+		})
+	})
+})
@@ -0,0 +1,389 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// ScrubStrategy defines how a sensitive value is replaced.
+type ScrubStrategy string
+
+const (
+	// ScrubStrategyMask replaces the sensitive value with ScrubOptions.Mask.
+	ScrubStrategyMask ScrubStrategy = "mask"
+	// ScrubStrategyHash replaces the sensitive value with a short sha256 hash, so equal
+	// values can still be correlated.
+	ScrubStrategyHash ScrubStrategy = "hash"
+	// ScrubStrategyDrop removes the tag or context entry. Sensitive parts of free text
+	// like messages are removed.
+	ScrubStrategyDrop ScrubStrategy = "drop"
+)
+
+// DefaultScrubKeyDenylist contains the key fragments (case insensitive) whose values are
+// always scrubbed.
+var DefaultScrubKeyDenylist = []string{
+	"password",
+	"token",
+	"authorization",
+	"cookie",
+	"secret",
+	"email",
+	"ip_address",
+	"username",
+}
+
+// ScrubPattern detects sensitive values inside arbitrary strings.
+type ScrubPattern struct {
+	// Regexp matches the sensitive part of a string.
+	Regexp *regexp.Regexp
+	// Validate optionally confirms a match, e.g. by a checksum. Nil accepts all matches.
+	Validate func(match string) bool
+}
+
+// DefaultScrubPatterns detects JWTs, credit card numbers, email addresses and AWS access keys.
+var DefaultScrubPatterns = []ScrubPattern{
+	{Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{Regexp: regexp.MustCompile(`\b(?:\d{4}[ -]?){3}\d{1,4}\b`), Validate: isLuhnValid},
+	{Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{Regexp: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+}
+
+// ScrubOptions configures the Scrubber created by NewScrubber.
+type ScrubOptions struct {
+	// KeyDenylist contains key fragments (case insensitive). Values of tags, context and
+	// breadcrumb data entries, request query parameters, headers and cookies and user
+	// fields (e.g. email, ip_address, username) with a matching key are scrubbed completely.
+	KeyDenylist []string
+	// Patterns detect sensitive values in tags, contexts, messages, exception values,
+	// breadcrumbs, the request and the user.
+	Patterns []ScrubPattern
+	// Strategy defines how sensitive values are replaced.
+	Strategy ScrubStrategy
+	// Mask replaces sensitive values if Strategy is ScrubStrategyMask.
+	Mask string
+}
+
+// Scrubber removes secrets and personal data from events before they are sent to Sentry.
+type Scrubber interface {
+	ScrubEvent(event *sentry.Event) *sentry.Event
+}
+
+// NewScrubber creates a Scrubber with DefaultScrubKeyDenylist, DefaultScrubPatterns and
+// ScrubStrategyMask. Set it as Options.Scrubber to scrub all events of a client after tag
+// enrichment.
+//
+//	client, err := sentry.NewClientWithOptions(ctx, clientOptions, func(options *sentry.Options) {
+//	    options.Scrubber = sentry.NewScrubber(func(options *sentry.ScrubOptions) {
+//	        options.Strategy = sentry.ScrubStrategyHash
+//	    })
+//	})
+func NewScrubber(optionFns ...func(options *ScrubOptions)) Scrubber {
+	options := ScrubOptions{
+		KeyDenylist: DefaultScrubKeyDenylist,
+		Patterns:    DefaultScrubPatterns,
+		Strategy:    ScrubStrategyMask,
+		Mask:        "[Filtered]",
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	keyDenylist := make([]string, 0, len(options.KeyDenylist))
+	for _, key := range options.KeyDenylist {
+		keyDenylist = append(keyDenylist, strings.ToLower(key))
+	}
+	options.KeyDenylist = keyDenylist
+	return &scrubber{
+		options: options,
+	}
+}
+
+type scrubber struct {
+	options ScrubOptions
+}
+
+func (s *scrubber) ScrubEvent(event *sentry.Event) *sentry.Event {
+	for key, value := range event.Tags {
+		scrubbed, keep := s.scrubValue(key, value)
+		if !keep {
+			delete(event.Tags, key)
+			continue
+		}
+		event.Tags[key] = scrubbed
+	}
+	for key, context := range event.Contexts {
+		event.Contexts[key] = s.scrubMap(context)
+	}
+	event.Message = s.scrubText(event.Message)
+	for i := range event.Exception {
+		event.Exception[i].Value = s.scrubText(event.Exception[i].Value)
+	}
+	for i, breadcrumb := range event.Breadcrumbs {
+		if breadcrumb == nil {
+			continue
+		}
+		// breadcrumbs are shared with the scope, so scrub a copy
+		scrubbed := *breadcrumb
+		scrubbed.Message = s.scrubText(breadcrumb.Message)
+		scrubbed.Data = s.scrubMap(breadcrumb.Data)
+		event.Breadcrumbs[i] = &scrubbed
+	}
+	if event.Request != nil {
+		event.Request = s.scrubRequest(event.Request)
+	}
+	event.User = s.scrubUser(event.User)
+	return event
+}
+
+// scrubRequest returns a scrubbed copy of the request, which can be shared with the scope,
+// e.g. of NewHTTPMiddleware.
+func (s *scrubber) scrubRequest(request *sentry.Request) *sentry.Request {
+	scrubbed := *request
+	scrubbed.URL = s.scrubURL(request.URL)
+	scrubbed.QueryString = s.scrubQuery(request.QueryString)
+	scrubbed.Cookies = s.scrubCookies(request.Cookies)
+	scrubbed.Headers = s.scrubStringMap(request.Headers)
+	scrubbed.Env = s.scrubStringMap(request.Env)
+	scrubbed.Data = s.scrubText(request.Data)
+	return &scrubbed
+}
+
+func (s *scrubber) scrubUser(user sentry.User) sentry.User {
+	user.ID = s.scrubField("id", user.ID)
+	user.Email = s.scrubField("email", user.Email)
+	user.IPAddress = s.scrubField("ip_address", user.IPAddress)
+	user.Username = s.scrubField("username", user.Username)
+	user.Name = s.scrubField("name", user.Name)
+	user.Data = s.scrubStringMap(user.Data)
+	return user
+}
+
+// scrubField scrubs the value of a field that can not be removed, dropped values are empty.
+func (s *scrubber) scrubField(key string, value string) string {
+	if value == "" {
+		return value
+	}
+	scrubbed, keep := s.scrubValue(key, value)
+	if !keep {
+		return ""
+	}
+	return scrubbed
+}
+
+// scrubURL scrubs the user info and query parameters of the given URL.
+func (s *scrubber) scrubURL(rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return s.scrubText(rawURL)
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), s.replace(""))
+	}
+	if path := s.scrubText(u.Path); path != u.Path {
+		u.Path = path
+		u.RawPath = ""
+	}
+	u.RawQuery = s.scrubQuery(u.RawQuery)
+	return u.String()
+}
+
+// scrubQuery scrubs the values of the given query string by their keys and patterns.
+// Unchanged parameters keep their original encoding.
+func (s *scrubber) scrubQuery(query string) string {
+	if query == "" {
+		return query
+	}
+	params := strings.Split(query, "&")
+	result := make([]string, 0, len(params))
+	for _, param := range params {
+		rawKey, rawValue, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+		scrubbed, keep := s.scrubValue(key, value)
+		switch {
+		case !keep:
+			continue
+		case scrubbed == value:
+			result = append(result, param)
+		default:
+			result = append(result, rawKey+"="+url.QueryEscape(scrubbed))
+		}
+	}
+	return strings.Join(result, "&")
+}
+
+// scrubCookies scrubs the values of a Cookie header by their names and patterns.
+func (s *scrubber) scrubCookies(cookies string) string {
+	if cookies == "" {
+		return cookies
+	}
+	parts := strings.Split(cookies, ";")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		scrubbed, keep := s.scrubValue(name, value)
+		if !keep {
+			continue
+		}
+		result = append(result, name+"="+scrubbed)
+	}
+	return strings.Join(result, "; ")
+}
+
+// scrubStringMap returns a scrubbed copy of the given map.
+func (s *scrubber) scrubStringMap(data map[string]string) map[string]string {
+	if data == nil {
+		return nil
+	}
+	result := make(map[string]string, len(data))
+	for key, value := range data {
+		scrubbed, keep := s.scrubValue(key, value)
+		if !keep {
+			continue
+		}
+		result[key] = scrubbed
+	}
+	return result
+}
+
+// scrubValue scrubs the value of the given key and returns false if it must be dropped.
+func (s *scrubber) scrubValue(key string, value string) (string, bool) {
+	if s.isDenied(key) {
+		if s.options.Strategy == ScrubStrategyDrop {
+			return "", false
+		}
+		return s.replace(value), true
+	}
+	scrubbed := s.scrubText(value)
+	if scrubbed != value && s.options.Strategy == ScrubStrategyDrop {
+		return "", false
+	}
+	return scrubbed, true
+}
+
+// scrubMap returns a scrubbed copy of the given data. Maps and slices are copied, because
+// they belong to the caller, e.g. hint or error data, and can be shared between goroutines.
+func (s *scrubber) scrubMap(data map[string]any) map[string]any {
+	if data == nil {
+		return nil
+	}
+	result := make(map[string]any, len(data))
+	for key, value := range data {
+		scrubbed, keep := s.scrubAny(key, value)
+		if !keep {
+			continue
+		}
+		result[key] = scrubbed
+	}
+	return result
+}
+
+// scrubAny returns a scrubbed copy of the given value and false if it must be dropped.
+func (s *scrubber) scrubAny(key string, value any) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		return s.scrubValue(key, v)
+	case map[string]any:
+		return s.scrubMap(v), true
+	case map[string]string:
+		return s.scrubStringMap(v), true
+	case []any:
+		result := make([]any, len(v))
+		for i, vv := range v {
+			result[i], _ = s.scrubAny(key, vv)
+		}
+		return result, true
+	case []string:
+		result := make([]string, len(v))
+		for i, vv := range v {
+			result[i], _ = s.scrubValue(key, vv)
+		}
+		return result, true
+	default:
+		if s.isDenied(key) {
+			return s.replace(""), s.options.Strategy != ScrubStrategyDrop
+		}
+		return value, true
+	}
+}
+
+// scrubText replaces all sensitive parts of the given free text.
+func (s *scrubber) scrubText(text string) string {
+	if text == "" {
+		return text
+	}
+	for _, pattern := range s.options.Patterns {
+		text = pattern.Regexp.ReplaceAllStringFunc(text, func(match string) string {
+			if pattern.Validate != nil && !pattern.Validate(match) {
+				return match
+			}
+			if s.options.Strategy == ScrubStrategyDrop {
+				return ""
+			}
+			return s.replace(match)
+		})
+	}
+	return text
+}
+
+func (s *scrubber) replace(value string) string {
+	if s.options.Strategy == ScrubStrategyHash {
+		sum := sha256.Sum256([]byte(value))
+		return "[sha256:" + hex.EncodeToString(sum[:8]) + "]"
+	}
+	return s.options.Mask
+}
+
+func (s *scrubber) isDenied(key string) bool {
+	key = strings.ToLower(key)
+	for _, denied := range s.options.KeyDenylist {
+		if strings.Contains(key, denied) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLuhnValid reports whether the digits of the given string pass the Luhn checksum.
+func isLuhnValid(value string) bool {
+	var sum int
+	var count int
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		count++
+		double = !double
+	}
+	return count >= 13 && sum%10 == 0
+}
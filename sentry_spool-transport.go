@@ -0,0 +1,564 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	stdtime "time"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+const (
+	spoolFileSuffix = ".envelope"
+	spoolTempSuffix = ".tmp"
+)
+
+// SpoolTransportOptions configures the transport created by NewSpoolTransport.
+type SpoolTransportOptions struct {
+	// MaxBytes is the maximum size of all spooled envelopes. The oldest envelopes are
+	// removed if the limit is exceeded.
+	MaxBytes int64
+	// MaxAge is the maximum age of a spooled envelope before it is dropped.
+	MaxAge stdtime.Duration
+	// RoundTripper is used to deliver envelopes, e.g. one created by NewProxyRoundTripper.
+	// Defaults to ClientOptions.HTTPTransport or http.DefaultTransport.
+	RoundTripper http.RoundTripper
+	// MinBackoff is the wait time after the first failed delivery.
+	MinBackoff stdtime.Duration
+	// MaxBackoff is the maximum wait time between retries. It is also the interval
+	// in which the spool directory is checked for envelopes of other processes.
+	MaxBackoff stdtime.Duration
+	// MaxReadAttempts is the number of failed reads of a spooled envelope that can not be
+	// read or is no valid envelope before it is dropped. Until then it is skipped, so it
+	// does not block the delivery of later envelopes.
+	MaxReadAttempts int
+	// RequestTimeout is the timeout of a single delivery. Close aborts a running delivery.
+	RequestTimeout stdtime.Duration
+	// Now returns the current time. Defaults to time.Now and can be replaced in tests.
+	Now func() stdtime.Time
+}
+
+// NewSpoolTransport creates a sentry.Transport that writes every event as serialized
+// envelope into the given directory before it is delivered. A background goroutine
+// sends the spooled envelopes to the DSN and retries with exponential backoff while
+// Sentry is unreachable. Envelopes left from a previous run are delivered after restart.
+//
+//	transport, err := sentry.NewSpoolTransport(ctx, "/var/spool/sentry")
+//	client, err := sentry.NewClient(ctx, sentry.ClientOptions{Dsn: dsn, Transport: transport})
+func NewSpoolTransport(
+	ctx context.Context,
+	directory string,
+	optionFns ...func(options *SpoolTransportOptions),
+) (sentry.Transport, error) {
+	options := SpoolTransportOptions{
+		MaxBytes:        50 * 1024 * 1024,
+		MaxAge:          24 * stdtime.Hour,
+		MinBackoff:      stdtime.Second,
+		MaxBackoff:      5 * stdtime.Minute,
+		MaxReadAttempts: 3,
+		RequestTimeout:  30 * stdtime.Second,
+		Now:             stdtime.Now,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	if err := os.MkdirAll(directory, 0o750); err != nil {
+		return nil, errors.Wrapf(ctx, err, "create spool directory %s failed", directory)
+	}
+	deliverCtx, cancel := context.WithCancel(context.Background())
+	transport := &spoolTransport{
+		directory: directory,
+		options:   options,
+		ctx:       deliverCtx,
+		cancel:    cancel,
+		failures:  make(map[string]int),
+		trigger:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	if err := transport.prune(); err != nil {
+		cancel()
+		return nil, errors.Wrapf(ctx, err, "load spool directory %s failed", directory)
+	}
+	return transport, nil
+}
+
+type spoolTransport struct {
+	directory string
+	options   SpoolTransportOptions
+	// ctx is canceled by Close and aborts a running delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mux        sync.Mutex
+	dsn        *sentry.Dsn
+	httpClient *http.Client
+	// files is the index of the spooled envelopes, oldest first, and total their size.
+	// The delivery loop reloads it from the spool directory every MaxBackoff.
+	files   []spoolFile
+	total   int64
+	scanned stdtime.Time
+	// failures counts the failed reads per path of a spooled envelope
+	failures map[string]int
+
+	trigger   chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once
+	started   bool
+}
+
+func (s *spoolTransport) Configure(options sentry.ClientOptions) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if options.Dsn != "" {
+		dsn, err := sentry.NewDsn(options.Dsn)
+		if err != nil {
+			glog.Warningf("parse sentry dsn failed: %v", err)
+		} else {
+			s.dsn = dsn
+		}
+	}
+	roundTripper := s.options.RoundTripper
+	if roundTripper == nil {
+		roundTripper = options.HTTPTransport
+	}
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+	s.httpClient = &http.Client{
+		Transport: roundTripper,
+		Timeout:   s.options.RequestTimeout,
+	}
+	s.startOnce.Do(func() {
+		s.started = true
+		go s.run()
+	})
+}
+
+func (s *spoolTransport) SendEvent(event *sentry.Event) {
+	content, err := encodeEnvelope(event)
+	if err != nil {
+		glog.Warningf("encode sentry event %s failed: %v", event.EventID, err)
+		return
+	}
+	if err := s.write(event.EventID, content); err != nil {
+		glog.Warningf("spool sentry event %s failed: %v", event.EventID, err)
+		return
+	}
+	s.notify()
+}
+
+func (s *spoolTransport) Flush(timeout stdtime.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.FlushWithContext(ctx)
+}
+
+// FlushWithContext waits until all spooled envelopes are delivered or the context is done.
+func (s *spoolTransport) FlushWithContext(ctx context.Context) bool {
+	s.notify()
+	ticker := stdtime.NewTicker(10 * stdtime.Millisecond)
+	defer ticker.Stop()
+	for {
+		if s.count() == 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *spoolTransport) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.cancel()
+		s.mux.Lock()
+		started := s.started
+		s.mux.Unlock()
+		if started {
+			<-s.stopped
+		}
+	})
+}
+
+func (s *spoolTransport) notify() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers spooled envelopes until the transport is closed.
+func (s *spoolTransport) run() {
+	defer close(s.stopped)
+	backoff := s.options.MinBackoff
+	for {
+		if err := s.deliverAll(); err != nil {
+			glog.V(2).Infof("deliver spooled sentry events failed, retry in %v: %v", backoff, err)
+			select {
+			case <-s.done:
+				return
+			case <-stdtime.After(backoff):
+			}
+			backoff = min(2*backoff, s.options.MaxBackoff)
+			continue
+		}
+		backoff = s.options.MinBackoff
+		select {
+		case <-s.done:
+			return
+		case <-s.trigger:
+		case <-stdtime.After(s.options.MaxBackoff):
+		}
+	}
+}
+
+// deliverAll sends all spooled envelopes, oldest first. It stops at the first
+// retryable failure. Envelopes that can not be read are skipped and retried later.
+func (s *spoolTransport) deliverAll() error {
+	if err := s.prune(); err != nil {
+		return err
+	}
+	s.mux.Lock()
+	files := slices.Clone(s.files)
+	s.mux.Unlock()
+	var result error
+	for _, file := range files {
+		select {
+		case <-s.done:
+			return nil
+		default:
+		}
+		content, err := s.read(file.path)
+		if err != nil {
+			result = s.readFailed(file.path, err)
+			continue
+		}
+		if content == nil {
+			continue
+		}
+		if err := s.deliver(file.path, content); err != nil {
+			return err
+		}
+	}
+	return result
+}
+
+// read returns the content of the spooled envelope or nil if it was removed meanwhile.
+func (s *spoolTransport) read(path string) ([]byte, error) {
+	ctx := s.ctx
+	content, err := os.ReadFile(path) // #nosec G304 -- path is listed from the spool directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(ctx, err, "read %s failed", path)
+	}
+	header, _, _ := bytes.Cut(content, []byte("\n"))
+	var envelopeHeader map[string]any
+	if err := json.Unmarshal(header, &envelopeHeader); err != nil {
+		return nil, errors.Wrapf(ctx, err, "parse envelope header of %s failed", path)
+	}
+	return content, nil
+}
+
+// readFailed counts the failed read and drops the envelope after MaxReadAttempts. It
+// returns the cause if the envelope is kept for a retry.
+func (s *spoolTransport) readFailed(path string, cause error) error {
+	s.mux.Lock()
+	s.failures[path]++
+	attempts := s.failures[path]
+	s.mux.Unlock()
+	if attempts < s.options.MaxReadAttempts {
+		glog.V(2).Infof("skip spooled sentry envelope %s (attempt %d): %v",
+			filepath.Base(path), attempts, cause)
+		return cause
+	}
+	glog.Warningf("drop spooled sentry envelope %s after %d failed reads: %v",
+		filepath.Base(path), attempts, cause)
+	return s.remove(path)
+}
+
+func (s *spoolTransport) deliver(path string, content []byte) error {
+	ctx := s.ctx
+	s.mux.Lock()
+	dsn := s.dsn
+	httpClient := s.httpClient
+	s.mux.Unlock()
+	if dsn == nil {
+		return errors.Errorf(ctx, "no sentry dsn configured")
+	}
+
+	req, err := newEnvelopeRequest(ctx, dsn, content)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(ctx, err, "send envelope %s failed", filepath.Base(path))
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		glog.V(3).Infof("delivered spooled sentry envelope %s", filepath.Base(path))
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return errors.Errorf(
+			ctx,
+			"send envelope %s failed with status %d",
+			filepath.Base(path),
+			resp.StatusCode,
+		)
+	default:
+		glog.Warningf(
+			"sentry rejected envelope %s with status %d => drop",
+			filepath.Base(path),
+			resp.StatusCode,
+		)
+	}
+	return s.remove(path)
+}
+
+// write spools the envelope and removes the oldest envelopes exceeding MaxBytes. Expired
+// envelopes are removed by the delivery loop.
+func (s *spoolTransport) write(eventID sentry.EventID, content []byte) error {
+	ctx := context.Background()
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.options.Now()
+	name := fmt.Sprintf("%020d-%s%s", now.UnixNano(), eventID, spoolFileSuffix)
+	path := filepath.Join(s.directory, name)
+	if err := os.WriteFile(path+spoolTempSuffix, content, 0o600); err != nil {
+		return errors.Wrapf(ctx, err, "write %s failed", path)
+	}
+	if err := os.Rename(path+spoolTempSuffix, path); err != nil {
+		return errors.Wrapf(ctx, err, "rename %s failed", path)
+	}
+	s.files = append(s.files, spoolFile{
+		name:    name,
+		path:    path,
+		size:    int64(len(content)),
+		created: now,
+	})
+	s.total += int64(len(content))
+	for s.oversizedLocked() {
+		if err := s.dropOldestLocked(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *spoolTransport) remove(path string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(context.Background(), err, "remove %s failed", path)
+	}
+	delete(s.failures, path)
+	for i, file := range s.files {
+		if file.path == path {
+			s.files = slices.Delete(s.files, i, i+1)
+			s.total -= file.size
+			break
+		}
+	}
+	return nil
+}
+
+func (s *spoolTransport) count() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return len(s.files)
+}
+
+// prune removes expired envelopes and the oldest envelopes exceeding MaxBytes. Every
+// MaxBackoff it first reloads the index from the spool directory, which can contain
+// envelopes of other processes.
+func (s *spoolTransport) prune() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := s.options.Now()
+	if s.scanned.IsZero() || now.Sub(s.scanned) >= s.options.MaxBackoff {
+		if err := s.reloadLocked(); err != nil {
+			return err
+		}
+		s.scanned = now
+	}
+	for len(s.files) > 0 {
+		expired := s.options.MaxAge > 0 && now.Sub(s.files[0].created) > s.options.MaxAge
+		if !expired && !s.oversizedLocked() {
+			return nil
+		}
+		if err := s.dropOldestLocked(expired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reloadLocked replaces the index with the envelopes of the spool directory.
+func (s *spoolTransport) reloadLocked() error {
+	files, err := s.list()
+	if err != nil {
+		return err
+	}
+	failures := make(map[string]int, len(s.failures))
+	s.files = files
+	s.total = 0
+	for _, file := range files {
+		s.total += file.size
+		if attempts, ok := s.failures[file.path]; ok {
+			failures[file.path] = attempts
+		}
+	}
+	s.failures = failures
+	return nil
+}
+
+func (s *spoolTransport) oversizedLocked() bool {
+	return s.options.MaxBytes > 0 && s.total > s.options.MaxBytes
+}
+
+func (s *spoolTransport) dropOldestLocked(expired bool) error {
+	oldest := s.files[0]
+	glog.Warningf("drop spooled sentry envelope %s (expired: %v)", oldest.name, expired)
+	if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(context.Background(), err, "remove %s failed", oldest.path)
+	}
+	delete(s.failures, oldest.path)
+	s.files = s.files[1:]
+	s.total -= oldest.size
+	return nil
+}
+
+type spoolFile struct {
+	name    string
+	path    string
+	size    int64
+	created stdtime.Time
+}
+
+// list returns all spooled envelopes, oldest first.
+func (s *spoolTransport) list() ([]spoolFile, error) {
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, errors.Wrapf(context.Background(), err, "read dir %s failed", s.directory)
+	}
+	files := make([]spoolFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, spoolFile{
+			name:    entry.Name(),
+			path:    filepath.Join(s.directory, entry.Name()),
+			size:    info.Size(),
+			created: spoolCreated(entry.Name(), info.ModTime()),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].name < files[j].name
+	})
+	return files, nil
+}
+
+// spoolCreated returns the time encoded in the name of a spooled envelope or the given
+// modification time if the name has no timestamp.
+func spoolCreated(name string, modTime stdtime.Time) stdtime.Time {
+	prefix, _, ok := strings.Cut(name, "-")
+	if !ok {
+		return modTime
+	}
+	nanos, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return modTime
+	}
+	return stdtime.Unix(0, nanos)
+}
+
+// encodeEnvelope serializes the event in the Sentry envelope format.
+func encodeEnvelope(event *sentry.Event) ([]byte, error) {
+	ctx := context.Background()
+	item, err := event.ToEnvelopeItem()
+	if err != nil {
+		return nil, errors.Wrapf(ctx, err, "create envelope item failed")
+	}
+	header, err := json.Marshal(map[string]any{
+		"event_id": event.EventID,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(ctx, err, "marshal envelope header failed")
+	}
+	itemHeader, err := json.Marshal(item.Header)
+	if err != nil {
+		return nil, errors.Wrapf(ctx, err, "marshal envelope item header failed")
+	}
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteString("\n")
+	buf.Write(itemHeader)
+	buf.WriteString("\n")
+	buf.Write(item.Payload)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// newEnvelopeRequest creates the request that sends the envelope to the envelope
+// endpoint of the given dsn.
+func newEnvelopeRequest(
+	ctx context.Context,
+	dsn *sentry.Dsn,
+	content []byte,
+) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		dsn.GetAPIURL().String(),
+		bytes.NewReader(content),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(ctx, err, "create request failed")
+	}
+	auth := fmt.Sprintf(
+		"Sentry sentry_version=7, sentry_client=sentry.go/%s, sentry_key=%s",
+		sentry.SDKVersion,
+		dsn.GetPublicKey(),
+	)
+	if dsn.GetSecretKey() != "" {
+		auth = fmt.Sprintf("%s, sentry_secret=%s", auth, dsn.GetSecretKey())
+	}
+	req.Header.Set("User-Agent", "sentry.go/"+sentry.SDKVersion)
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", auth)
+	return req, nil
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+)
+
+var _ = Describe("SpoolTransport", func() {
+	var ctx context.Context
+	var directory string
+	var server *httptest.Server
+	var up atomic.Bool
+	var blocked atomic.Bool
+	var started atomic.Int64
+	var mux sync.Mutex
+	var received []string
+	var dsn string
+	var newClient func() sentry.Client
+	var spoolFiles func() []string
+	BeforeEach(func() {
+		ctx = context.Background()
+		directory = GinkgoT().TempDir()
+		received = nil
+		up.Store(true)
+		blocked.Store(false)
+		started.Store(0)
+		server = httptest.NewServer(
+			http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				started.Add(1)
+				body, _ := io.ReadAll(req.Body)
+				if blocked.Load() {
+					<-req.Context().Done()
+					return
+				}
+				if !up.Load() {
+					resp.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				mux.Lock()
+				received = append(received, string(body))
+				mux.Unlock()
+				resp.WriteHeader(http.StatusOK)
+			}),
+		)
+		dsn = strings.Replace(server.URL, "http://", "http://public@", 1) + "/1"
+		newClient = func() sentry.Client {
+			transport, err := sentry.NewSpoolTransport(
+				ctx,
+				directory,
+				func(options *sentry.SpoolTransportOptions) {
+					options.MinBackoff = 10 * time.Millisecond
+					options.MaxBackoff = 20 * time.Millisecond
+				},
+			)
+			Expect(err).To(BeNil())
+			client, err := sentry.NewClientWithOptions(
+				ctx,
+				libsentry.ClientOptions{
+					Dsn:       dsn,
+					Transport: transport,
+				},
+				func(options *sentry.Options) {
+					options.CloseTimeout = 50 * time.Millisecond
+				},
+			)
+			Expect(err).To(BeNil())
+			return client
+		}
+		spoolFiles = func() []string {
+			entries, err := os.ReadDir(directory)
+			Expect(err).To(BeNil())
+			var result []string
+			for _, entry := range entries {
+				result = append(result, entry.Name())
+			}
+			return result
+		}
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+	receivedCount := func() int {
+		mux.Lock()
+		defer mux.Unlock()
+		return len(received)
+	}
+	It("delivers events while sentry is up", func() {
+		client := newClient()
+		defer client.Close()
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Flush(time.Second)).To(BeTrue())
+		Expect(receivedCount()).To(Equal(1))
+		Expect(received[0]).To(ContainSubstring(`"type":"event"`))
+		Expect(received[0]).To(ContainSubstring("banana"))
+		Expect(spoolFiles()).To(BeEmpty())
+	})
+	It("keeps events while sentry is down and delivers them later", func() {
+		up.Store(false)
+		client := newClient()
+		defer client.Close()
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Flush(100 * time.Millisecond)).To(BeFalse())
+		Expect(spoolFiles()).To(HaveLen(1))
+		Expect(receivedCount()).To(Equal(0))
+
+		up.Store(true)
+		Expect(client.Flush(time.Second)).To(BeTrue())
+		Expect(receivedCount()).To(Equal(1))
+		Expect(spoolFiles()).To(BeEmpty())
+	})
+	It("resumes delivery after restart", func() {
+		up.Store(false)
+		client := newClient()
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Flush(50 * time.Millisecond)).To(BeFalse())
+		var undelivered *sentry.UndeliveredEventsError
+		Expect(stderrors.As(client.Close(), &undelivered)).To(BeTrue())
+		Expect(undelivered.Pending).To(Equal(1))
+		Expect(spoolFiles()).To(HaveLen(1))
+
+		up.Store(true)
+		client = newClient()
+		defer client.Close()
+		Expect(client.Flush(time.Second)).To(BeTrue())
+		Expect(receivedCount()).To(Equal(1))
+	})
+	It("delivers later events and drops a corrupt envelope", func() {
+		Expect(os.WriteFile(
+			filepath.Join(directory, "00000000000000000001-corrupt.envelope"),
+			[]byte("banana"),
+			0o600,
+		)).To(Succeed())
+		client := newClient()
+		defer client.Close()
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Flush(time.Second)).To(BeTrue())
+		Expect(receivedCount()).To(Equal(1))
+		Expect(received[0]).To(ContainSubstring(`"type":"event"`))
+		Expect(spoolFiles()).To(BeEmpty())
+	})
+	It("drops oldest events above max bytes", func() {
+		up.Store(false)
+		transport, err := sentry.NewSpoolTransport(
+			ctx,
+			directory,
+			func(options *sentry.SpoolTransportOptions) {
+				options.MaxBytes = 1
+			},
+		)
+		Expect(err).To(BeNil())
+		transport.SendEvent(&libsentry.Event{EventID: "1", Message: "first"})
+		transport.SendEvent(&libsentry.Event{EventID: "2", Message: "second"})
+		Expect(spoolFiles()).To(BeEmpty())
+		transport.Close()
+	})
+	It("drops expired events", func() {
+		up.Store(false)
+		var nowMux sync.Mutex
+		now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		transport, err := sentry.NewSpoolTransport(
+			ctx,
+			directory,
+			func(options *sentry.SpoolTransportOptions) {
+				options.MaxAge = time.Hour
+				options.MinBackoff = 10 * time.Millisecond
+				options.MaxBackoff = 20 * time.Millisecond
+				options.Now = func() time.Time {
+					nowMux.Lock()
+					defer nowMux.Unlock()
+					return now
+				}
+			},
+		)
+		Expect(err).To(BeNil())
+		defer transport.Close()
+		transport.Configure(libsentry.ClientOptions{Dsn: dsn})
+		transport.SendEvent(&libsentry.Event{EventID: "1", Message: "first"})
+		nowMux.Lock()
+		now = now.Add(2 * time.Hour)
+		nowMux.Unlock()
+		transport.SendEvent(&libsentry.Event{EventID: "2", Message: "second"})
+		Eventually(spoolFiles).Should(ConsistOf(HaveSuffix("-2.envelope")))
+	})
+	It("aborts a running delivery on close", func() {
+		blocked.Store(true)
+		transport, err := sentry.NewSpoolTransport(ctx, directory)
+		Expect(err).To(BeNil())
+		transport.Configure(libsentry.ClientOptions{Dsn: dsn})
+		transport.SendEvent(&libsentry.Event{EventID: "1", Message: "first"})
+		Eventually(started.Load).Should(Equal(int64(1)))
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			transport.Close()
+		}()
+		Eventually(closed).Should(BeClosed())
+		Expect(spoolFiles()).To(HaveLen(1))
+	})
+	It("stops delivering after the client is closed", func() {
+		up.Store(false)
+		client := newClient()
+		client.CaptureException(stderrors.New("banana"), nil, nil)
+		Expect(client.Close()).NotTo(BeNil())
+
+		up.Store(true)
+		Consistently(receivedCount, 100*time.Millisecond).Should(Equal(0))
+		Expect(spoolFiles()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+)
+
+const (
+	// MaxTagKeyLength is the maximum length of a tag key accepted by Sentry.
+	MaxTagKeyLength = 32
+	// MaxTagValueLength is the maximum length of a tag value accepted by Sentry.
+	MaxTagValueLength = 200
+
+	// TagNormalizationContext is the event context listing all tags altered by the
+	// normalization, keyed by the original tag key.
+	TagNormalizationContext = "tag_normalization"
+	// OversizedTagsContext is the event context containing tag values moved by
+	// TagOverflowContext.
+	OversizedTagsContext = "oversized_tags"
+
+	// emptyTagKey is the key of a dropped tag with empty key in TagNormalizationContext.
+	emptyTagKey = "(empty)"
+)
+
+// TagOverflow defines how tag values longer than MaxTagValueLength are handled.
+type TagOverflow string
+
+const (
+	// TagOverflowTruncate cuts the value to MaxTagValueLength characters.
+	TagOverflowTruncate TagOverflow = "truncate"
+	// TagOverflowContext removes the tag and stores the full value in the event context
+	// OversizedTagsContext.
+	TagOverflowContext TagOverflow = "context"
+)
+
+// newTagNormalizer creates an event processor that makes all tags acceptable for Sentry.
+// Keys are restricted to letters, digits, '_', '.', ':' and '-' and cut to MaxTagKeyLength.
+// Line breaks and tabs in values are replaced by spaces and oversized values are handled by
+// the given TagOverflow. Tags with empty key are dropped. Every altered tag is recorded in
+// context TagNormalizationContext.
+func newTagNormalizer(overflow TagOverflow) sentry.EventProcessor {
+	return func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+		normalizer := &tagNormalizer{
+			overflow:  overflow,
+			tags:      make(map[string]string, len(event.Tags)),
+			altered:   make(sentry.Context),
+			oversized: make(sentry.Context),
+		}
+		keys := make([]string, 0, len(event.Tags))
+		for key := range event.Tags {
+			keys = append(keys, key)
+		}
+		// valid keys first, so they win over renamed keys
+		sort.Slice(keys, func(i, j int) bool {
+			iValid := normalizeTagKey(keys[i]) == keys[i]
+			jValid := normalizeTagKey(keys[j]) == keys[j]
+			if iValid != jValid {
+				return iValid
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys {
+			normalizer.add(key, event.Tags[key])
+		}
+		event.Tags = normalizer.tags
+		if len(normalizer.altered) == 0 {
+			return event
+		}
+		if event.Contexts == nil {
+			event.Contexts = make(map[string]sentry.Context)
+		}
+		event.Contexts[TagNormalizationContext] = normalizer.altered
+		if len(normalizer.oversized) > 0 {
+			event.Contexts[OversizedTagsContext] = normalizer.oversized
+		}
+		return event
+	}
+}
+
+type tagNormalizer struct {
+	overflow  TagOverflow
+	tags      map[string]string
+	altered   sentry.Context
+	oversized sentry.Context
+}
+
+func (t *tagNormalizer) add(key string, value string) {
+	var changes []string
+	normalizedKey := normalizeTagKey(key)
+	if normalizedKey == "" {
+		t.altered[emptyTagKey] = fmt.Sprintf("dropped, empty key with value %s", value)
+		return
+	}
+	if normalizedKey != key {
+		changes = append(changes, fmt.Sprintf("key renamed to %s", normalizedKey))
+	}
+	if _, exists := t.tags[normalizedKey]; exists {
+		t.altered[key] = fmt.Sprintf("dropped, key %s already exists", normalizedKey)
+		return
+	}
+	normalizedValue := normalizeTagValue(value)
+	if normalizedValue != value {
+		changes = append(changes, "line breaks replaced")
+	}
+	if length := utf8.RuneCountInString(normalizedValue); length > MaxTagValueLength {
+		if t.overflow == TagOverflowContext {
+			t.oversized[normalizedKey] = value
+			t.altered[key] = fmt.Sprintf(
+				"value with %d characters moved to context %s",
+				length,
+				OversizedTagsContext,
+			)
+			return
+		}
+		normalizedValue = truncateTagValue(normalizedValue)
+		changes = append(changes, fmt.Sprintf("value truncated from %d characters", length))
+	}
+	t.tags[normalizedKey] = normalizedValue
+	if len(changes) > 0 {
+		t.altered[key] = strings.Join(changes, ", ")
+	}
+}
+
+func normalizeTagKey(key string) string {
+	var builder strings.Builder
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			builder.WriteRune(r)
+		case r == '_', r == '.', r == ':', r == '-':
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('_')
+		}
+	}
+	result := builder.String()
+	if len(result) > MaxTagKeyLength {
+		result = result[:MaxTagKeyLength]
+	}
+	return result
+}
+
+func normalizeTagValue(value string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(value)
+}
+
+// truncateTagValue cuts the value to MaxTagValueLength characters ending with an ellipsis.
+func truncateTagValue(value string) string {
+	runes := []rune(value)
+	return string(runes[:MaxTagValueLength-1]) + "…"
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("Tag normalization", func() {
+	var ctx context.Context
+	var recorder *sentrytest.Recorder
+	var overflow sentry.TagOverflow
+	var event *libsentry.Event
+	longValue := strings.Repeat("x", 250)
+	BeforeEach(func() {
+		overflow = sentry.TagOverflowTruncate
+	})
+	JustBeforeEach(func() {
+		recorder = sentrytest.NewRecorderWithOptions(func(options *sentry.Options) {
+			options.TagOverflow = overflow
+		})
+		ctx = context.Background()
+		ctx = errors.AddToContext(ctx, "user id", "123")
+		ctx = errors.AddToContext(ctx, "query", "select *\nfrom users")
+		ctx = errors.AddToContext(ctx, "this_key_is_much_longer_than_thirty_two", "v")
+		ctx = errors.AddToContext(ctx, "long", longValue)
+		ctx = errors.AddToContext(ctx, "service", "my-app")
+		ctx = errors.AddToContext(ctx, "", "orphan")
+		recorder.CaptureException(stderrors.New("banana"), &libsentry.EventHint{Context: ctx}, nil)
+		Expect(recorder.Events()).To(HaveLen(1))
+		event = recorder.Events()[0]
+	})
+	It("keeps valid tags", func() {
+		Expect(event.Tags).To(HaveKeyWithValue("service", "my-app"))
+		Expect(event.Contexts[sentry.TagNormalizationContext]).NotTo(HaveKey("service"))
+	})
+	It("replaces invalid key characters", func() {
+		Expect(event.Tags).To(HaveKeyWithValue("user_id", "123"))
+		Expect(event.Contexts[sentry.TagNormalizationContext]).To(
+			HaveKeyWithValue("user id", "key renamed to user_id"),
+		)
+	})
+	It("drops tags with empty key", func() {
+		Expect(event.Tags).NotTo(HaveKey(""))
+		Expect(event.Contexts[sentry.TagNormalizationContext]).To(
+			HaveKeyWithValue("(empty)", "dropped, empty key with value orphan"),
+		)
+	})
+	It("truncates long keys", func() {
+		Expect(event.Tags).To(HaveKey("this_key_is_much_longer_than_thi"))
+	})
+	It("replaces line breaks", func() {
+		Expect(event.Tags).To(HaveKeyWithValue("query", "select * from users"))
+	})
+	It("truncates long values", func() {
+		Expect([]rune(event.Tags["long"])).To(HaveLen(sentry.MaxTagValueLength))
+		Expect(event.Contexts[sentry.TagNormalizationContext]).To(
+			HaveKeyWithValue("long", "value truncated from 250 characters"),
+		)
+	})
+	Context("overflow to context", func() {
+		BeforeEach(func() {
+			overflow = sentry.TagOverflowContext
+		})
+		It("moves long values", func() {
+			Expect(event.Tags).NotTo(HaveKey("long"))
+			Expect(event.Contexts[sentry.OversizedTagsContext]).To(
+				HaveKeyWithValue("long", longValue),
+			)
+		})
+	})
+	It("prefers valid keys on collision", func() {
+		scope := libsentry.NewScope()
+		scope.SetTag("a b", "renamed")
+		scope.SetTag("a_b", "original")
+		recorder.CaptureMessage("hello", nil, scope)
+		Expect(recorder.Events()).To(HaveLen(2))
+		Expect(recorder.Events()[1].Tags).To(HaveKeyWithValue("a_b", "original"))
+	})
+})
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+)
+
+// TagCollisionsContext is the event context recording all values of colliding tags,
+// keyed by tag and DataSource.
+const TagCollisionsContext = "tag_collisions"
+
+// DataSource names a source of tags. The value is used as suffix by TagCollisionSuffix.
+type DataSource string
+
+const (
+	// DataSourceEvent are the tags already on the event, set by ClientOptions.Tags or scope.
+	DataSourceEvent DataSource = "event"
+	// DataSourceContext is the data of hint.Context added with github.com/bborbe/errors.
+	DataSourceContext DataSource = "ctx"
+	// DataSourceError is the data attached to hint.OriginalException.
+	DataSourceError DataSource = "err"
+	// DataSourceHint is hint.Data, a map with string like keys, a struct or a TagProvider.
+	DataSourceHint DataSource = "hint"
+)
+
+// DefaultDataPrecedence is the order in which tag sources are applied, later sources have
+// higher precedence.
+var DefaultDataPrecedence = []DataSource{
+	DataSourceEvent,
+	DataSourceContext,
+	DataSourceError,
+	DataSourceHint,
+}
+
+// TagCollision defines what happens if a source sets a tag that already has another value.
+type TagCollision string
+
+const (
+	// TagCollisionOverwrite replaces the value, so the source with highest precedence wins.
+	TagCollisionOverwrite TagCollision = "overwrite"
+	// TagCollisionKeepFirst keeps the value, so the source with lowest precedence wins.
+	TagCollisionKeepFirst TagCollision = "keep-first"
+	// TagCollisionSuffix keeps the value and adds the new one with the source as suffix,
+	// e.g. key.ctx or key.err.
+	TagCollisionSuffix TagCollision = "suffix"
+)
+
+// tagMerger applies the tags of all sources in precedence order to the event.
+type tagMerger struct {
+	event      *sentry.Event
+	collision  TagCollision
+	router     DataRouter
+	sources    map[string]DataSource
+	collisions sentry.Context
+}
+
+func (t *tagMerger) merge(source DataSource, data map[string]any) {
+	for key, value := range data {
+		if source != DataSourceEvent && t.router(key, value) == DataRouteContext {
+			addData(t.event, t.router, key, value)
+			continue
+		}
+		tags := make(map[string]string)
+		addTag(tags, key, value)
+		for k, v := range tags {
+			t.set(source, k, v)
+		}
+	}
+}
+
+func (t *tagMerger) set(source DataSource, key string, value string) {
+	existing, ok := t.event.Tags[key]
+	if !ok || existing == value {
+		t.event.Tags[key] = value
+		t.sources[key] = source
+		return
+	}
+	t.recordCollision(key, t.sources[key], existing)
+	t.recordCollision(key, source, value)
+	switch t.collision {
+	case TagCollisionKeepFirst:
+	case TagCollisionSuffix:
+		t.event.Tags[key+"."+string(source)] = value
+	default:
+		t.event.Tags[key] = value
+		t.sources[key] = source
+	}
+}
+
+func (t *tagMerger) recordCollision(key string, source DataSource, value string) {
+	values, ok := t.collisions[key].(map[string]string)
+	if !ok {
+		values = make(map[string]string)
+		t.collisions[key] = values
+	}
+	values[string(source)] = value
+}
+
+// dataFromSource returns the data of the given source.
+func dataFromSource(source DataSource, event *sentry.Event, hint *sentry.EventHint) map[string]any {
+	switch source {
+	case DataSourceEvent:
+		result := make(map[string]any, len(event.Tags))
+		for k, v := range event.Tags {
+			result[k] = v
+		}
+		return result
+	case DataSourceContext:
+		if hint.Context == nil {
+			return nil
+		}
+		return errors.DataFromContext(hint.Context)
+	case DataSourceError:
+		if hint.OriginalException == nil {
+			return nil
+		}
+		return errors.DataFromError(hint.OriginalException)
+	case DataSourceHint:
+		return hintData(hint)
+	default:
+		return nil
+	}
+}
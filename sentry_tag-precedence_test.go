@@ -0,0 +1,104 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+
+	"github.com/bborbe/errors"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("Tag precedence", func() {
+	var collision sentry.TagCollision
+	var precedence []sentry.DataSource
+	var event *libsentry.Event
+	BeforeEach(func() {
+		collision = sentry.TagCollisionOverwrite
+		precedence = sentry.DefaultDataPrecedence
+	})
+	JustBeforeEach(func() {
+		recorder := sentrytest.NewRecorderWithOptions(func(options *sentry.Options) {
+			options.TagCollision = collision
+			options.DataPrecedence = precedence
+		})
+		ctx := errors.AddToContext(context.Background(), "tenant", "ctx-tenant")
+		ctx = errors.AddToContext(ctx, "service", "ctx-service")
+		err := errors.AddDataToError(errors.New(context.Background(), "banana"), map[string]any{
+			"tenant": "err-tenant",
+		})
+		scope := libsentry.NewScope()
+		scope.SetTag("service", "scope-service")
+		scope.SetTag("team", "core")
+		recorder.CaptureException(
+			err,
+			&libsentry.EventHint{
+				Context: ctx,
+				Data:    map[string]string{"tenant": "hint-tenant"},
+			},
+			scope,
+		)
+		Expect(recorder.Events()).To(HaveLen(1))
+		event = recorder.Events()[0]
+	})
+	Context("overwrite", func() {
+		It("uses the source with highest precedence", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("tenant", "hint-tenant"))
+			Expect(event.Tags).To(HaveKeyWithValue("service", "ctx-service"))
+			Expect(event.Tags).To(HaveKeyWithValue("team", "core"))
+		})
+		It("records all values of collisions", func() {
+			Expect(event.Contexts[sentry.TagCollisionsContext]).To(HaveKeyWithValue(
+				"tenant",
+				map[string]string{"ctx": "ctx-tenant", "err": "err-tenant", "hint": "hint-tenant"},
+			))
+			Expect(event.Contexts[sentry.TagCollisionsContext]).To(HaveKeyWithValue(
+				"service",
+				map[string]string{"event": "scope-service", "ctx": "ctx-service"},
+			))
+			Expect(event.Contexts[sentry.TagCollisionsContext]).NotTo(HaveKey("team"))
+		})
+	})
+	Context("keep first", func() {
+		BeforeEach(func() {
+			collision = sentry.TagCollisionKeepFirst
+		})
+		It("uses the source with lowest precedence", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("tenant", "ctx-tenant"))
+			Expect(event.Tags).To(HaveKeyWithValue("service", "scope-service"))
+		})
+	})
+	Context("suffix", func() {
+		BeforeEach(func() {
+			collision = sentry.TagCollisionSuffix
+		})
+		It("adds conflicting values with source suffix", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("tenant", "ctx-tenant"))
+			Expect(event.Tags).To(HaveKeyWithValue("tenant.err", "err-tenant"))
+			Expect(event.Tags).To(HaveKeyWithValue("tenant.hint", "hint-tenant"))
+			Expect(event.Tags).To(HaveKeyWithValue("service", "scope-service"))
+			Expect(event.Tags).To(HaveKeyWithValue("service.ctx", "ctx-service"))
+		})
+	})
+	Context("custom precedence", func() {
+		BeforeEach(func() {
+			precedence = []sentry.DataSource{
+				sentry.DataSourceHint,
+				sentry.DataSourceError,
+				sentry.DataSourceContext,
+				sentry.DataSourceEvent,
+			}
+		})
+		It("applies sources in the given order", func() {
+			Expect(event.Tags).To(HaveKeyWithValue("tenant", "ctx-tenant"))
+			Expect(event.Tags).To(HaveKeyWithValue("service", "scope-service"))
+		})
+	})
+})
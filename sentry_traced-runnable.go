@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"context"
+
+	"github.com/bborbe/errors"
+	"github.com/bborbe/run"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// NewTracedRunnable creates a run.Func that runs the given action in a transaction with
+// the given operation and name, started by the given client. The context passed to the
+// action contains the transaction, so nested code can add spans with StartChildSpan. The
+// status of the transaction is set from the error returned by the action; a panic marks
+// it as internal error and continues panicking.
+//
+//	sentry.NewTracedRunnable(sentryClient, "job", "daily-report", reportRunnable)
+func NewTracedRunnable(
+	sentryClient Client,
+	operation string,
+	name string,
+	action run.Runnable,
+) run.Func {
+	return func(ctx context.Context) error {
+		transaction := sentryClient.StartTransaction(ctx, name, sentry.WithOpName(operation))
+		completed := false
+		defer func() {
+			if !completed {
+				// the action panics, finish the transaction and continue panicking
+				transaction.Status = sentry.SpanStatusInternalError
+			}
+			transaction.Finish()
+		}()
+		err := action.Run(transaction.Context())
+		completed = true
+		transaction.Status = spanStatus(err)
+		if err != nil {
+			glog.V(2).Infof("traced run of %s failed: %v", name, err)
+		}
+		return err
+	}
+}
+
+// StartChildSpan starts a span with the given operation as child of the span in the given
+// context, e.g. the transaction of NewTracedRunnable. The span is finished by calling
+// Finish. Without span in the context the returned span is not sampled and never sent.
+//
+//	span := sentry.StartChildSpan(ctx, "db.query")
+//	defer span.Finish()
+func StartChildSpan(
+	ctx context.Context,
+	operation string,
+	options ...sentry.SpanOption,
+) *sentry.Span {
+	if sentry.SpanFromContext(ctx) == nil {
+		options = append(options, sentry.WithSpanSampled(sentry.SampledFalse))
+	}
+	return sentry.StartSpan(ctx, operation, options...)
+}
+
+// spanStatus returns the span status for the given error of a run.
+func spanStatus(err error) sentry.SpanStatus {
+	switch {
+	case err == nil:
+		return sentry.SpanStatusOK
+	case errors.Is(err, context.Canceled):
+		return sentry.SpanStatusCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return sentry.SpanStatusDeadlineExceeded
+	default:
+		return sentry.SpanStatusInternalError
+	}
+}
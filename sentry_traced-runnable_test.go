@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry_test
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+
+	"github.com/bborbe/run"
+	libsentry "github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("TracedRunnable", func() {
+	var ctx context.Context
+	var recorder *sentrytest.Recorder
+	var actionErr error
+	var runnable run.Func
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = sentrytest.NewRecorder()
+		actionErr = nil
+		runnable = sentry.NewTracedRunnable(
+			recorder,
+			"job",
+			"daily-report",
+			run.Func(func(ctx context.Context) error {
+				span := sentry.StartChildSpan(ctx, "db.query")
+				span.Finish()
+				if actionErr != nil {
+					recorder.CaptureException(actionErr, &libsentry.EventHint{Context: ctx}, nil)
+				}
+				return actionErr
+			}),
+		)
+	})
+	Context("success", func() {
+		var transaction *libsentry.Event
+		BeforeEach(func() {
+			Expect(runnable.Run(ctx)).To(Succeed())
+			Expect(recorder.Transactions()).To(HaveLen(1))
+			transaction = recorder.Transactions()[0]
+		})
+		It("sends the transaction", func() {
+			Expect(transaction.Transaction).To(Equal("daily-report"))
+			Expect(transaction.Contexts["trace"]).To(HaveKeyWithValue("op", "job"))
+			Expect(transaction.Contexts["trace"]).To(HaveKeyWithValue("status", libsentry.SpanStatusOK))
+		})
+		It("contains the child span", func() {
+			Expect(transaction.Spans).To(HaveLen(1))
+			Expect(transaction.Spans[0].Op).To(Equal("db.query"))
+		})
+		It("records no events", func() {
+			Expect(recorder.Events()).To(BeEmpty())
+		})
+	})
+	Context("failure", func() {
+		var err error
+		BeforeEach(func() {
+			actionErr = stderrors.New("banana")
+			err = runnable.Run(ctx)
+		})
+		It("returns the error", func() {
+			Expect(err).To(MatchError("banana"))
+		})
+		It("sets the status", func() {
+			Expect(recorder.Transactions()).To(HaveLen(1))
+			Expect(recorder.Transactions()[0].Contexts["trace"]).To(
+				HaveKeyWithValue("status", libsentry.SpanStatusInternalError),
+			)
+		})
+		It("links captured errors to the trace", func() {
+			Expect(recorder.Events()).To(HaveLen(1))
+			traceID := recorder.Transactions()[0].Contexts["trace"]["trace_id"]
+			Expect(recorder.Events()[0].Contexts["trace"]).To(HaveKeyWithValue("trace_id", traceID))
+		})
+	})
+	It("sets status canceled for canceled runs", func() {
+		actionErr = context.Canceled
+		Expect(runnable.Run(ctx)).To(MatchError(context.Canceled))
+		Expect(recorder.Transactions()[0].Contexts["trace"]).To(
+			HaveKeyWithValue("status", libsentry.SpanStatusCanceled),
+		)
+	})
+	It("sends the transaction of a panicking run and repanics", func() {
+		runnable = sentry.NewTracedRunnable(
+			recorder,
+			"job",
+			"daily-report",
+			run.Func(func(ctx context.Context) error {
+				panic("boom")
+			}),
+		)
+		Expect(func() { _ = runnable.Run(ctx) }).To(PanicWith("boom"))
+		Expect(recorder.Transactions()).To(HaveLen(1))
+		Expect(recorder.Transactions()[0].Contexts["trace"]).To(
+			HaveKeyWithValue("status", libsentry.SpanStatusInternalError),
+		)
+	})
+	Context("sampler", func() {
+		var mux sync.Mutex
+		var transactions []*libsentry.Event
+		var sampleRate float64
+		collect := func(event *libsentry.Event, hint *libsentry.EventHint) *libsentry.Event {
+			mux.Lock()
+			defer mux.Unlock()
+			transactions = append(transactions, event)
+			return event
+		}
+		BeforeEach(func() {
+			transactions = nil
+			client, err := sentry.NewClient(ctx, libsentry.ClientOptions{
+				EnableTracing: true,
+				TracesSampler: func(samplingContext libsentry.SamplingContext) float64 {
+					Expect(samplingContext.Span.Op).To(Equal("job"))
+					return sampleRate
+				},
+				BeforeSendTransaction: collect,
+			})
+			Expect(err).To(BeNil())
+			runnable = sentry.NewTracedRunnable(
+				client,
+				"job",
+				"daily-report",
+				run.Func(func(ctx context.Context) error { return nil }),
+			)
+		})
+		It("sends sampled transactions", func() {
+			sampleRate = 1
+			Expect(runnable.Run(ctx)).To(Succeed())
+			Expect(transactions).To(HaveLen(1))
+		})
+		It("drops unsampled transactions", func() {
+			sampleRate = 0
+			Expect(runnable.Run(ctx)).To(Succeed())
+			Expect(transactions).To(BeEmpty())
+		})
+	})
+	It("does not sample child spans without parent", func() {
+		span := sentry.StartChildSpan(ctx, "db.query")
+		Expect(span.Sampled).To(Equal(libsentry.SampledFalse))
+		span.Finish()
+	})
+})
@@ -0,0 +1,30 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentry
+
+import (
+	"fmt"
+	stdtime "time"
+)
+
+// UndeliveredEventsError is returned by Client.Close if the transport did not deliver all
+// events within Options.CloseTimeout.
+//
+//	var undelivered *sentry.UndeliveredEventsError
+//	if errors.As(client.Close(), &undelivered) {
+//	    glog.Warningf("%d sentry events maybe lost", undelivered.Pending)
+//	}
+type UndeliveredEventsError struct {
+	// Pending is the number of events passed to the transport whose delivery was neither
+	// confirmed by a successful request to Sentry nor by a successful Flush. Transports not
+	// using ClientOptions.HTTPTransport confirm events only by Flush.
+	Pending int
+	// Timeout is the duration Close waited for the delivery.
+	Timeout stdtime.Duration
+}
+
+func (e *UndeliveredEventsError) Error() string {
+	return fmt.Sprintf("sentry events not delivered within %v (%d pending)", e.Timeout, e.Pending)
+}
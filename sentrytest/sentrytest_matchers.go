@@ -0,0 +1,202 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentrytest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// HaveCapturedException succeeds if the Recorder captured at least one exception whose
+// original error matches the given matcher. A non matcher value is compared with
+// gomega.MatchError.
+//
+//	Expect(recorder).To(HaveCapturedException(MatchError("banana")))
+func HaveCapturedException(expected any) types.GomegaMatcher {
+	return &recordsMatcher{
+		name: "captured exception",
+		matcher: toMatcher(expected, func(expected any) types.GomegaMatcher {
+			return gomega.MatchError(expected)
+		}),
+		extract: func(record Record) (any, bool) {
+			err := record.Exception()
+			return err, err != nil
+		},
+	}
+}
+
+// HaveCapturedMessage succeeds if the Recorder captured at least one event whose message
+// matches the given matcher. A non matcher value is compared with gomega.Equal.
+//
+//	Expect(recorder).To(HaveCapturedMessage(ContainSubstring("banana")))
+func HaveCapturedMessage(expected any) types.GomegaMatcher {
+	return &recordsMatcher{
+		name:    "captured message",
+		matcher: toMatcher(expected, gomega.Equal),
+		extract: func(record Record) (any, bool) {
+			return record.Event.Message, record.Event.Message != ""
+		},
+	}
+}
+
+// HaveCapturedEvent succeeds if the Recorder captured at least one event that matches
+// the given matcher.
+//
+//	Expect(recorder).To(HaveCapturedEvent(HaveTag("service", "my-app")))
+func HaveCapturedEvent(matcher types.GomegaMatcher) types.GomegaMatcher {
+	return &recordsMatcher{
+		name:    "captured event",
+		matcher: matcher,
+		extract: func(record Record) (any, bool) {
+			return record.Event, true
+		},
+	}
+}
+
+// HaveTag succeeds if the actual *sentry.Event has a tag with the given key and a value
+// matching the given value. A non matcher value is compared with gomega.Equal.
+//
+//	Expect(event).To(HaveTag("service", "my-app"))
+func HaveTag(key string, value any) types.GomegaMatcher {
+	return &tagMatcher{
+		key:     key,
+		matcher: toMatcher(value, gomega.Equal),
+	}
+}
+
+func toMatcher(expected any, fallback func(any) types.GomegaMatcher) types.GomegaMatcher {
+	if matcher, ok := expected.(types.GomegaMatcher); ok {
+		return matcher
+	}
+	return fallback(expected)
+}
+
+type recordsMatcher struct {
+	name    string
+	matcher types.GomegaMatcher
+	extract func(record Record) (any, bool)
+}
+
+func (m *recordsMatcher) Match(actual any) (bool, error) {
+	records, err := recordsOf(actual)
+	if err != nil {
+		return false, err
+	}
+	for _, record := range records {
+		value, ok := m.extract(record)
+		if !ok {
+			continue
+		}
+		success, err := m.matcher.Match(value)
+		if err != nil {
+			return false, err
+		}
+		if success {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *recordsMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf(
+		"Expected %s matching\n%s\nin\n%s",
+		m.name,
+		format.Object(m.matcher, 1),
+		format.Object(describeRecords(actual), 1),
+	)
+}
+
+func (m *recordsMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf(
+		"Expected no %s matching\n%s\nin\n%s",
+		m.name,
+		format.Object(m.matcher, 1),
+		format.Object(describeRecords(actual), 1),
+	)
+}
+
+func recordsOf(actual any) ([]Record, error) {
+	switch v := actual.(type) {
+	case *Recorder:
+		return v.Records(), nil
+	case []Record:
+		return v, nil
+	default:
+		return nil, errors.Errorf(
+			context.Background(),
+			"expected *sentrytest.Recorder or []sentrytest.Record, got\n%s",
+			format.Object(actual, 1),
+		)
+	}
+}
+
+func describeRecords(actual any) []string {
+	records, err := recordsOf(actual)
+	if err != nil {
+		return nil
+	}
+	result := make([]string, 0, len(records))
+	for _, record := range records {
+		if err := record.Exception(); err != nil {
+			result = append(result, fmt.Sprintf("exception: %v", err))
+			continue
+		}
+		result = append(result, fmt.Sprintf("message: %s", record.Event.Message))
+	}
+	return result
+}
+
+type tagMatcher struct {
+	key     string
+	matcher types.GomegaMatcher
+}
+
+func (m *tagMatcher) Match(actual any) (bool, error) {
+	event, ok := actual.(*sentry.Event)
+	if !ok {
+		return false, errors.Errorf(
+			context.Background(),
+			"expected *sentry.Event, got\n%s",
+			format.Object(actual, 1),
+		)
+	}
+	value, ok := event.Tags[m.key]
+	if !ok {
+		return false, nil
+	}
+	return m.matcher.Match(value)
+}
+
+func (m *tagMatcher) FailureMessage(actual any) string {
+	return fmt.Sprintf(
+		"Expected tag %q matching\n%s\nin\n%s",
+		m.key,
+		format.Object(m.matcher, 1),
+		format.Object(tagsOf(actual), 1),
+	)
+}
+
+func (m *tagMatcher) NegatedFailureMessage(actual any) string {
+	return fmt.Sprintf(
+		"Expected no tag %q matching\n%s\nin\n%s",
+		m.key,
+		format.Object(m.matcher, 1),
+		format.Object(tagsOf(actual), 1),
+	)
+}
+
+func tagsOf(actual any) map[string]string {
+	if event, ok := actual.(*sentry.Event); ok {
+		return event.Tags
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sentrytest provides test helpers for code that reports to Sentry via
+// github.com/bborbe/sentry.
+//
+// The Recorder is a real sentry.Client that runs the complete enrichment pipeline,
+// exclusion list and scope application, but keeps the final events in memory instead
+// of sending them to Sentry. Combined with the gomega matchers of this package it
+// allows asserting on exactly what would reach Sentry without a DSN:
+//
+//	recorder := sentrytest.NewRecorder()
+//	service := NewService(recorder)
+//	Expect(service.Run(ctx)).To(Succeed())
+//	Expect(recorder).To(sentrytest.HaveCapturedException(MatchError("banana")))
+//	Expect(recorder.Events()[0]).To(sentrytest.HaveTag("service", "my-app"))
+//
+// The Sink is a Sentry compatible ingest server. NewServer starts it on a local port for
+// tests that need the real transport, cmd/sentry-sink runs it for local development.
+package sentrytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	libsentry "github.com/bborbe/sentry"
+)
+
+// Record is a single event captured by the Recorder together with the hint it was
+// captured with.
+type Record struct {
+	Event *sentry.Event
+	Hint  *sentry.EventHint
+}
+
+// Exception returns the original error of the record or nil if the event was not
+// created from an error.
+func (r Record) Exception() error {
+	if r.Hint == nil {
+		return nil
+	}
+	return r.Hint.OriginalException
+}
+
+// NewRecorder creates a Recorder with the given ExcludeError functions.
+func NewRecorder(excludeErrors ...libsentry.ExcludeError) *Recorder {
+	return NewRecorderWithOptions(func(options *libsentry.Options) {
+		options.ExcludeErrors = excludeErrors
+	})
+}
+
+// NewRecorderWithOptions creates a Recorder configured like a client of
+// libsentry.NewClientWithOptions.
+func NewRecorderWithOptions(optionFns ...func(options *libsentry.Options)) *Recorder {
+	recorder := &Recorder{
+		hints: make(map[sentry.EventID]*sentry.EventHint),
+	}
+	client, err := libsentry.NewClientWithOptions(
+		context.Background(),
+		sentry.ClientOptions{
+			Transport:        &transport{recorder: recorder},
+			BeforeSend:       recorder.beforeSend,
+			EnableTracing:    true,
+			TracesSampleRate: 1.0,
+		},
+		optionFns...,
+	)
+	if err != nil {
+		// can only fail with an invalid dsn, which the recorder never sets
+		panic(err)
+	}
+	recorder.Client = client
+	return recorder
+}
+
+// Recorder is an in-memory sentry.Client for tests. All captured events pass the
+// same pipeline as with a real client and are stored after it instead of being sent.
+type Recorder struct {
+	libsentry.Client
+
+	mux          sync.Mutex
+	hints        map[sentry.EventID]*sentry.EventHint
+	records      []Record
+	checkIns     []*sentry.CheckIn
+	transactions []*sentry.Event
+}
+
+// Records returns all captured records in the order they were captured.
+func (r *Recorder) Records() []Record {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	result := make([]Record, len(r.records))
+	copy(result, r.records)
+	return result
+}
+
+// Events returns all captured events in the order they were captured.
+func (r *Recorder) Events() []*sentry.Event {
+	records := r.Records()
+	result := make([]*sentry.Event, 0, len(records))
+	for _, record := range records {
+		result = append(result, record.Event)
+	}
+	return result
+}
+
+// Exceptions returns the original errors of all captured exception events.
+func (r *Recorder) Exceptions() []error {
+	var result []error
+	for _, record := range r.Records() {
+		if err := record.Exception(); err != nil {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// CheckIns returns all captured check-ins in the order they were captured. Check-ins are
+// not part of Records and Events.
+func (r *Recorder) CheckIns() []*sentry.CheckIn {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	result := make([]*sentry.CheckIn, len(r.checkIns))
+	copy(result, r.checkIns)
+	return result
+}
+
+// Transactions returns all finished transactions in the order they were sent. All
+// transactions are sampled. Transactions are not part of Records and Events.
+func (r *Recorder) Transactions() []*sentry.Event {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	result := make([]*sentry.Event, len(r.transactions))
+	copy(result, r.transactions)
+	return result
+}
+
+// Reset removes all captured events, check-ins and transactions.
+func (r *Recorder) Reset() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.hints = make(map[sentry.EventID]*sentry.EventHint)
+	r.records = nil
+	r.checkIns = nil
+	r.transactions = nil
+}
+
+func (r *Recorder) beforeSend(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.hints[event.EventID] = hint
+	return event
+}
+
+func (r *Recorder) record(event *sentry.Event) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if event.CheckIn != nil {
+		checkIn := *event.CheckIn
+		r.checkIns = append(r.checkIns, &checkIn)
+		return
+	}
+	if event.Type == "transaction" {
+		r.transactions = append(r.transactions, event)
+		return
+	}
+	hint := r.hints[event.EventID]
+	delete(r.hints, event.EventID)
+	r.records = append(r.records, Record{
+		Event: event,
+		Hint:  hint,
+	})
+}
+
+// transport is the sentry.Transport of the Recorder. It stores events synchronously
+// instead of sending them.
+type transport struct {
+	recorder *Recorder
+}
+
+func (t *transport) Configure(options sentry.ClientOptions) {}
+
+func (t *transport) SendEvent(event *sentry.Event) {
+	t.recorder.record(event)
+}
+
+func (t *transport) Flush(timeout time.Duration) bool {
+	return true
+}
+
+func (t *transport) FlushWithContext(ctx context.Context) bool {
+	return true
+}
+
+func (t *transport) Close() {}
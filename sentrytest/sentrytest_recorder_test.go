@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentrytest_test
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("Recorder", func() {
+	var ctx context.Context
+	var recorder *sentrytest.Recorder
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = sentrytest.NewRecorder(func(err error) bool {
+			return stderrors.Is(err, context.Canceled)
+		})
+	})
+	Context("CaptureException", func() {
+		var err error
+		var eventID *sentry.EventID
+		BeforeEach(func() {
+			ctx = errors.AddToContext(ctx, "context-key", "context-value")
+			err = errors.AddDataToError(
+				stderrors.New("banana"),
+				map[string]any{"error-key": "error-value"},
+			)
+		})
+		JustBeforeEach(func() {
+			scope := sentry.NewScope()
+			scope.SetTag("scope-key", "scope-value")
+			eventID = recorder.CaptureException(
+				err,
+				&sentry.EventHint{
+					Context: ctx,
+					Data:    map[string]any{"hint-key": 1337},
+				},
+				scope,
+			)
+		})
+		It("returns event id", func() {
+			Expect(eventID).NotTo(BeNil())
+		})
+		It("records one event", func() {
+			Expect(recorder.Events()).To(HaveLen(1))
+			Expect(*eventID).To(Equal(recorder.Events()[0].EventID))
+		})
+		It("records the original exception", func() {
+			Expect(recorder).To(sentrytest.HaveCapturedException(MatchError("banana")))
+			Expect(recorder).To(sentrytest.HaveCapturedException("banana"))
+			Expect(recorder).NotTo(sentrytest.HaveCapturedException("apple"))
+			Expect(recorder.Exceptions()).To(HaveLen(1))
+		})
+		It("enriches tags", func() {
+			event := recorder.Events()[0]
+			Expect(event).To(sentrytest.HaveTag("context-key", "context-value"))
+			Expect(event).To(sentrytest.HaveTag("error-key", "error-value"))
+			Expect(event).To(sentrytest.HaveTag("hint-key", "1337"))
+			Expect(event).To(sentrytest.HaveTag("scope-key", "scope-value"))
+			Expect(event).NotTo(sentrytest.HaveTag("missing", "value"))
+		})
+		It("matches captured event", func() {
+			Expect(recorder).To(sentrytest.HaveCapturedEvent(
+				sentrytest.HaveTag("context-key", HavePrefix("context")),
+			))
+		})
+		Context("excluded error", func() {
+			BeforeEach(func() {
+				err = errors.Wrap(ctx, context.Canceled, "wrap")
+			})
+			It("returns no event id", func() {
+				Expect(eventID).To(BeNil())
+			})
+			It("records nothing", func() {
+				Expect(recorder.Events()).To(BeEmpty())
+				Expect(recorder).NotTo(sentrytest.HaveCapturedException(context.Canceled))
+			})
+		})
+	})
+	Context("CaptureMessage", func() {
+		BeforeEach(func() {
+			recorder.CaptureMessage("hello world", &sentry.EventHint{Context: ctx}, sentry.NewScope())
+		})
+		It("records message", func() {
+			Expect(recorder).To(sentrytest.HaveCapturedMessage("hello world"))
+			Expect(recorder).To(sentrytest.HaveCapturedMessage(ContainSubstring("world")))
+			Expect(recorder).NotTo(sentrytest.HaveCapturedException(Not(BeNil())))
+		})
+		It("removes all records on reset", func() {
+			recorder.Reset()
+			Expect(recorder.Records()).To(BeEmpty())
+		})
+	})
+	Context("HaveTag", func() {
+		It("returns error for non event", func() {
+			_, err := sentrytest.HaveTag("key", "value").Match("banana")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentrytest
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+)
+
+// NewServer starts a Sink on a local port for tests. It panics if the Sink can not be
+// created, like httptest.NewServer does if it can not listen.
+//
+//	server := sentrytest.NewServer()
+//	defer server.Close()
+//	client, err := sentry.NewClient(ctx, sentry.ClientOptions{Dsn: server.DSN()})
+//	...
+//	Expect(client.Flush(time.Second)).To(BeTrue())
+//	Expect(server.Query(sentrytest.SinkQuery{Level: "error"})).To(HaveLen(1))
+func NewServer(optionFns ...func(options *SinkOptions)) *Server {
+	sink, err := NewSink(context.Background(), optionFns...)
+	if err != nil {
+		panic(fmt.Sprintf("sentrytest: create sink failed: %v", err))
+	}
+	server := httptest.NewServer(sink)
+	return &Server{
+		Sink:   sink,
+		URL:    server.URL,
+		server: server,
+	}
+}
+
+// Server is a Sink listening on a local port.
+type Server struct {
+	*Sink
+	// URL is the base URL of the server, e.g. http://127.0.0.1:1234.
+	URL string
+
+	server *httptest.Server
+}
+
+// DSN returns a DSN of project 1 pointing at the server.
+func (s *Server) DSN() string {
+	return strings.Replace(s.URL, "://", "://public@", 1) + "/1"
+}
+
+// Close stops the server and closes the Sink.
+func (s *Server) Close() error {
+	s.server.Close()
+	return s.Sink.Close()
+}
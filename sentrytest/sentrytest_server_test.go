@@ -0,0 +1,229 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentrytest_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	libsentry "github.com/bborbe/sentry"
+	"github.com/bborbe/sentry/sentrytest"
+)
+
+var _ = Describe("Server", func() {
+	var ctx context.Context
+	var server *sentrytest.Server
+	var optionFns []func(options *sentrytest.SinkOptions)
+	BeforeEach(func() {
+		ctx = context.Background()
+		optionFns = nil
+	})
+	JustBeforeEach(func() {
+		server = sentrytest.NewServer(optionFns...)
+	})
+	AfterEach(func() {
+		Expect(server.Close()).To(Succeed())
+	})
+	post := func(path string, contentEncoding string, body []byte) *http.Response {
+		req, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodPost,
+			server.URL+path,
+			bytes.NewReader(body),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(resp.Body.Close)
+		return resp
+	}
+	get := func(path string) (*http.Response, []byte) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp, body
+	}
+	It("receives events of a client", func() {
+		client, err := libsentry.NewClient(ctx, sentry.ClientOptions{
+			Dsn:  server.DSN(),
+			Tags: map[string]string{"service": "banana"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+		client.CaptureException(stderrors.New("banana failed"), nil, sentry.NewScope())
+		client.CaptureMessage("hello", nil, sentry.NewScope())
+		Expect(client.Flush(5 * time.Second)).To(BeTrue())
+
+		Eventually(server.Events).Should(HaveLen(2))
+		events := server.Query(sentrytest.SinkQuery{Tags: map[string]string{"service": "banana"}})
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].ProjectID).To(Equal("1"))
+		Expect(events[0].Type).To(Equal(sentrytest.SinkEventTypeEvent))
+		Expect(events[0].Level).To(Equal("error"))
+		Expect(events[0].Message).To(ContainSubstring("banana failed"))
+		Expect(events[1].Message).To(Equal("hello"))
+		event, err := events[0].Event()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Exception).NotTo(BeEmpty())
+	})
+	It("receives gzip compressed envelopes with check-ins", func() {
+		var body bytes.Buffer
+		writer := gzip.NewWriter(&body)
+		payload := `{"check_in_id":"abc","monitor_slug":"my-job","status":"ok"}`
+		_, err := io.WriteString(writer, "{}\n"+
+			`{"type":"client_report"}`+"\n{}\n"+
+			`{"type":"check_in","length":`+strconv.Itoa(len(payload))+"}\n"+payload+"\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		resp := post("/api/42/envelope/", "gzip", body.Bytes())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(server.Events()).To(HaveLen(1))
+		Expect(server.Events()[0].Type).To(Equal(sentrytest.SinkEventTypeCheckIn))
+		Expect(server.Events()[0].EventID).To(Equal("abc"))
+		Expect(server.Events()[0].ProjectID).To(Equal("42"))
+		Expect(server.Events()[0].Message).To(Equal("my-job ok"))
+	})
+	It("receives events of the store endpoint", func() {
+		resp := post("/api/1/store/", "", []byte(`{"event_id":"e1","level":"warning",`+
+			`"message":"disk full","tags":[["host","a"]]}`))
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		var result map[string]string
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+		Expect(result).To(HaveKeyWithValue("id", "e1"))
+		Expect(server.Events()).To(HaveLen(1))
+		Expect(server.Events()[0].Tags).To(HaveKeyWithValue("host", "a"))
+	})
+	It("rejects invalid payloads", func() {
+		resp := post("/api/1/store/", "", []byte(`banana`))
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		resp = post("/api/1/store/", "br", []byte(`{}`))
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(server.Events()).To(BeEmpty())
+	})
+	It("rejects envelope items with invalid length", func() {
+		for _, length := range []string{"-1", "1000"} {
+			resp := post("/api/1/envelope/", "", []byte("{}\n"+
+				`{"type":"event","length":`+length+"}\n"+`{"event_id":"e1"}`+"\n"))
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		}
+		Expect(server.Events()).To(BeEmpty())
+	})
+	Context("MaxBodySize", func() {
+		BeforeEach(func() {
+			optionFns = append(optionFns, func(options *sentrytest.SinkOptions) {
+				options.MaxBodySize = 1024
+			})
+		})
+		It("rejects too large bodies", func() {
+			resp := post("/api/1/store/", "", bytes.Repeat([]byte(" "), 2048))
+			Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+		It("rejects bodies too large after decompression", func() {
+			var body bytes.Buffer
+			writer := gzip.NewWriter(&body)
+			_, err := writer.Write(bytes.Repeat([]byte(" "), 64*1024))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+			Expect(body.Len()).To(BeNumerically("<", 1024))
+
+			resp := post("/api/1/store/", "gzip", body.Bytes())
+			Expect(resp.StatusCode).To(Equal(http.StatusRequestEntityTooLarge))
+		})
+	})
+	Context("with events", func() {
+		JustBeforeEach(func() {
+			post("/api/1/store/", "", []byte(`{"event_id":"e1","level":"error",`+
+				`"message":"banana failed","tags":{"service":"a"}}`))
+			post("/api/1/store/", "", []byte(`{"event_id":"e2","level":"info",`+
+				`"message":"apple ok","tags":{"service":"b"}}`))
+		})
+		It("queries events by tag", func() {
+			resp, body := get("/api/events?tag=service:b")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			var events []sentrytest.SinkEvent
+			Expect(json.Unmarshal(body, &events)).To(Succeed())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].EventID).To(Equal("e2"))
+		})
+		It("queries events by level and message", func() {
+			_, body := get("/api/events?level=error&message=BANANA")
+			var events []sentrytest.SinkEvent
+			Expect(json.Unmarshal(body, &events)).To(Succeed())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].EventID).To(Equal("e1"))
+
+			_, body = get("/api/events?level=fatal")
+			Expect(string(body)).To(Equal("[]"))
+		})
+		It("lists events in the ui", func() {
+			resp, body := get("/")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(string(body)).To(ContainSubstring("banana failed"))
+			Expect(string(body)).To(ContainSubstring("apple ok"))
+
+			resp, body = get("/events/e1")
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(string(body)).To(ContainSubstring("banana failed"))
+
+			resp, _ = get("/events/unknown")
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+	Context("MaxEvents", func() {
+		BeforeEach(func() {
+			optionFns = append(optionFns, func(options *sentrytest.SinkOptions) {
+				options.MaxEvents = 1
+			})
+		})
+		It("keeps only the newest events", func() {
+			post("/api/1/store/", "", []byte(`{"event_id":"e1","message":"first"}`))
+			post("/api/1/store/", "", []byte(`{"event_id":"e2","message":"second"}`))
+			Expect(server.Events()).To(HaveLen(1))
+			Expect(server.Events()[0].EventID).To(Equal("e2"))
+		})
+	})
+	Context("JSONLPath", func() {
+		var path string
+		BeforeEach(func() {
+			path = filepath.Join(GinkgoT().TempDir(), "events.jsonl")
+			optionFns = append(optionFns, func(options *sentrytest.SinkOptions) {
+				options.JSONLPath = path
+			})
+		})
+		It("writes and loads events", func() {
+			post("/api/1/store/", "", []byte(`{"event_id":"e1","message":"first"}`))
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`"event_id":"e1"`))
+
+			sink, err := sentrytest.NewSink(ctx, optionFns...)
+			Expect(err).NotTo(HaveOccurred())
+			defer sink.Close()
+			Expect(sink.Events()).To(HaveLen(1))
+			Expect(sink.Events()[0].Message).To(Equal("first"))
+		})
+	})
+})
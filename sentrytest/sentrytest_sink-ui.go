@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentrytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+func (s *Sink) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/{project}/store/", s.handleStore)
+	mux.HandleFunc("POST /api/{project}/envelope/", s.handleEnvelope)
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("GET /events/{id}", s.handleEvent)
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	return mux
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>sentry-sink</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px; text-align: left; vertical-align: top; }
+.tag { background: #eee; border-radius: 3px; margin-right: 4px; padding: 0 4px; }
+</style>
+</head>
+<body>
+<h1>sentry-sink</h1>
+<form method="get">
+<input name="message" placeholder="message" value="{{ .Query.Message }}">
+<input name="level" placeholder="level" value="{{ .Query.Level }}">
+<input name="type" placeholder="type" value="{{ .Query.Type }}">
+<input name="tag" placeholder="key:value" value="{{ .Tag }}">
+<button type="submit">filter</button>
+</form>
+<p>{{ len .Events }} events</p>
+<table>
+<tr><th>received</th><th>type</th><th>level</th><th>message</th><th>tags</th></tr>
+{{ range .Events }}
+<tr>
+<td>{{ .ReceivedAt.Format "2006-01-02 15:04:05" }}</td>
+<td>{{ .Type }}</td>
+<td>{{ .Level }}</td>
+<td><a href="events/{{ .EventID }}">{{ .Message }}</a></td>
+<td>{{ range $key, $value := .Tags }}<span class="tag">{{ $key }}={{ $value }}</span>{{ end }}</td>
+</tr>
+{{ end }}
+</table>
+</body>
+</html>
+`))
+
+var eventTemplate = template.Must(template.New("event").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{ .Event.Message }}</title></head>
+<body style="font-family: sans-serif; margin: 1em;">
+<p><a href="../">back</a></p>
+<h1>{{ .Event.Message }}</h1>
+<pre>{{ .Payload }}</pre>
+</body>
+</html>
+`))
+
+// handleIndex lists the events matching the query, newest first.
+func (s *Sink) handleIndex(resp http.ResponseWriter, req *http.Request) {
+	query := queryFromRequest(req)
+	events := s.Query(query)
+	slices.Reverse(events)
+	resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := indexTemplate.Execute(resp, struct {
+		Query  SinkQuery
+		Tag    string
+		Events []SinkEvent
+	}{
+		Query:  query,
+		Tag:    strings.Join(req.URL.Query()["tag"], ","),
+		Events: events,
+	})
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleEvent shows the payload of the event with the given id.
+func (s *Sink) handleEvent(resp http.ResponseWriter, req *http.Request) {
+	id := req.PathValue("id")
+	for _, event := range s.Events() {
+		if event.EventID != id {
+			continue
+		}
+		var payload bytes.Buffer
+		if err := json.Indent(&payload, event.Payload, "", "  "); err != nil {
+			payload.Reset()
+			payload.Write(event.Payload)
+		}
+		resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := eventTemplate.Execute(resp, struct {
+			Event   SinkEvent
+			Payload string
+		}{
+			Event:   event,
+			Payload: payload.String(),
+		})
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	http.NotFound(resp, req)
+}
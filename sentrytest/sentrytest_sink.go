@@ -0,0 +1,557 @@
+// Copyright (c) 2026 Benjamin Borbe All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sentrytest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bborbe/errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/golang/glog"
+)
+
+// Types of events stored by the Sink.
+const (
+	SinkEventTypeEvent       = "event"
+	SinkEventTypeTransaction = "transaction"
+	SinkEventTypeCheckIn     = "check_in"
+)
+
+// SinkOptions configures the Sink created by NewSink.
+type SinkOptions struct {
+	// MaxEvents is the number of events kept in memory, older events are removed.
+	// Defaults to 1000.
+	MaxEvents int
+	// MaxBodySize is the maximum size of a request body in bytes, compressed and after
+	// decompression. Defaults to 20 MiB.
+	MaxBodySize int64
+	// JSONLPath is a file every received event is appended to as JSON line. Events of an
+	// existing file are loaded on start. Empty keeps the events only in memory.
+	JSONLPath string
+	// Now returns the current time. Defaults to time.Now and can be replaced in tests.
+	Now func() time.Time
+}
+
+// SinkEvent is an event received by the Sink.
+type SinkEvent struct {
+	ReceivedAt time.Time         `json:"received_at"`
+	ProjectID  string            `json:"project_id"`
+	Type       string            `json:"type"`
+	EventID    string            `json:"event_id"`
+	Level      string            `json:"level,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	// Payload is the event as sent by the SDK.
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Event decodes the payload of an event or transaction.
+func (e SinkEvent) Event() (*sentry.Event, error) {
+	var event sentry.Event
+	if err := json.Unmarshal(e.Payload, &event); err != nil {
+		return nil, errors.Wrapf(context.Background(), err, "decode event %s failed", e.EventID)
+	}
+	return &event, nil
+}
+
+// SinkQuery selects events of the Sink. Empty fields match all events.
+type SinkQuery struct {
+	// Type is the type of the event, e.g. SinkEventTypeEvent.
+	Type string
+	// Level is the level of the event, e.g. error.
+	Level string
+	// Message is contained in the message of the event, ignoring case.
+	Message string
+	// Tags are the tags the event must have.
+	Tags map[string]string
+}
+
+// Matches returns true if the given event matches the query.
+func (q SinkQuery) Matches(event SinkEvent) bool {
+	if q.Type != "" && q.Type != event.Type {
+		return false
+	}
+	if q.Level != "" && q.Level != event.Level {
+		return false
+	}
+	if q.Message != "" &&
+		!strings.Contains(strings.ToLower(event.Message), strings.ToLower(q.Message)) {
+		return false
+	}
+	for key, value := range q.Tags {
+		if event.Tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSink creates a Sink. Serve it with an http.Server or use NewServer in tests.
+func NewSink(ctx context.Context, optionFns ...func(options *SinkOptions)) (*Sink, error) {
+	options := SinkOptions{
+		MaxEvents:   1000,
+		MaxBodySize: 20 * 1024 * 1024,
+		Now:         time.Now,
+	}
+	for _, optionFn := range optionFns {
+		optionFn(&options)
+	}
+	sink := &Sink{
+		options: options,
+	}
+	if options.JSONLPath != "" {
+		if err := sink.load(ctx); err != nil {
+			return nil, errors.Wrapf(ctx, err, "load events failed")
+		}
+		file, err := os.OpenFile(options.JSONLPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "open %s failed", options.JSONLPath)
+		}
+		sink.file = file
+	}
+	sink.mux = sink.routes()
+	return sink, nil
+}
+
+// Sink is a Sentry compatible ingest server for development and tests. It accepts events
+// of the store and envelope endpoints for any DSN pointing at it, keeps them in memory and
+// optionally in a JSON lines file. Received events are listed by a small HTML UI at / and
+// queried by the JSON API at /api/events with parameters type, level, message and
+// tag=key:value.
+type Sink struct {
+	options SinkOptions
+	mux     *http.ServeMux
+
+	mutex  sync.Mutex
+	events []SinkEvent
+	file   *os.File
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Sink) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	s.mux.ServeHTTP(resp, req)
+}
+
+// Events returns all stored events in the order they were received.
+func (s *Sink) Events() []SinkEvent {
+	return s.Query(SinkQuery{})
+}
+
+// Query returns the stored events matching the given query in the order they were received.
+func (s *Sink) Query(query SinkQuery) []SinkEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []SinkEvent
+	for _, event := range s.events {
+		if query.Matches(event) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// Reset removes all events from memory. The JSON lines file is kept.
+func (s *Sink) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = nil
+}
+
+// Close closes the JSON lines file.
+func (s *Sink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *Sink) add(event SinkEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.append(event)
+	if s.file == nil {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		glog.Warningf("encode event %s failed: %v", event.EventID, err)
+		return
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		glog.Warningf("write event %s to %s failed: %v", event.EventID, s.options.JSONLPath, err)
+	}
+}
+
+// append adds the event in memory and removes the oldest events above MaxEvents.
+func (s *Sink) append(event SinkEvent) {
+	s.events = append(s.events, event)
+	if s.options.MaxEvents > 0 && len(s.events) > s.options.MaxEvents {
+		s.events = s.events[len(s.events)-s.options.MaxEvents:]
+	}
+}
+
+// load reads the events of an existing JSON lines file.
+func (s *Sink) load(ctx context.Context) error {
+	file, err := os.Open(s.options.JSONLPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(ctx, err, "open %s failed", s.options.JSONLPath)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var event SinkEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			glog.Warningf("skip invalid line of %s: %v", s.options.JSONLPath, err)
+			continue
+		}
+		s.append(event)
+	}
+	return scanner.Err()
+}
+
+func (s *Sink) handleStore(resp http.ResponseWriter, req *http.Request) {
+	body, err := readBody(resp, req, s.options.MaxBodySize)
+	if err != nil {
+		writeBodyError(resp, err)
+		return
+	}
+	event, err := s.newEvent(req.PathValue("project"), "", body)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.add(event)
+	writeJSON(resp, map[string]string{"id": event.EventID})
+}
+
+func (s *Sink) handleEnvelope(resp http.ResponseWriter, req *http.Request) {
+	body, err := readBody(resp, req, s.options.MaxBodySize)
+	if err != nil {
+		writeBodyError(resp, err)
+		return
+	}
+	items, err := parseEnvelope(req.Context(), body)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var eventID string
+	for _, item := range items {
+		switch item.itemType {
+		case SinkEventTypeEvent, SinkEventTypeTransaction, SinkEventTypeCheckIn:
+		default:
+			glog.V(4).Infof("ignore envelope item of type %s", item.itemType)
+			continue
+		}
+		event, err := s.newEvent(req.PathValue("project"), item.itemType, item.payload)
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.add(event)
+		eventID = event.EventID
+	}
+	writeJSON(resp, map[string]string{"id": eventID})
+}
+
+func (s *Sink) handleEvents(resp http.ResponseWriter, req *http.Request) {
+	events := s.Query(queryFromRequest(req))
+	if events == nil {
+		events = []SinkEvent{}
+	}
+	writeJSON(resp, events)
+}
+
+// queryFromRequest reads the query of the parameters type, level, message and
+// tag=key:value.
+func queryFromRequest(req *http.Request) SinkQuery {
+	values := req.URL.Query()
+	query := SinkQuery{
+		Type:    values.Get("type"),
+		Level:   values.Get("level"),
+		Message: values.Get("message"),
+	}
+	for _, tag := range values["tag"] {
+		key, value, _ := strings.Cut(tag, ":")
+		if key == "" {
+			continue
+		}
+		if query.Tags == nil {
+			query.Tags = make(map[string]string)
+		}
+		query.Tags[key] = value
+	}
+	return query
+}
+
+// sinkPayload contains the fields of event, transaction and check-in payloads used by the
+// Sink.
+type sinkPayload struct {
+	EventID     string          `json:"event_id"`
+	CheckInID   string          `json:"check_in_id"`
+	Type        string          `json:"type"`
+	Level       string          `json:"level"`
+	Message     string          `json:"message"`
+	Transaction string          `json:"transaction"`
+	MonitorSlug string          `json:"monitor_slug"`
+	Status      string          `json:"status"`
+	Tags        json.RawMessage `json:"tags"`
+	Exception   json.RawMessage `json:"exception"`
+	Logentry    struct {
+		Message   string `json:"message"`
+		Formatted string `json:"formatted"`
+	} `json:"logentry"`
+}
+
+type sinkException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (s *Sink) newEvent(projectID string, itemType string, body []byte) (SinkEvent, error) {
+	var payload sinkPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return SinkEvent{}, errors.Wrapf(context.Background(), err, "decode payload failed")
+	}
+	event := SinkEvent{
+		ReceivedAt: s.options.Now(),
+		ProjectID:  projectID,
+		Type:       itemType,
+		EventID:    payload.EventID,
+		Level:      payload.Level,
+		Tags:       decodeTags(payload.Tags),
+		Payload:    json.RawMessage(bytes.Clone(body)),
+	}
+	if event.Type == "" {
+		event.Type = SinkEventTypeEvent
+		if payload.Type == SinkEventTypeTransaction {
+			event.Type = SinkEventTypeTransaction
+		}
+	}
+	switch {
+	case event.Type == SinkEventTypeCheckIn:
+		event.EventID = payload.CheckInID
+		event.Message = payload.MonitorSlug + " " + payload.Status
+	case event.Type == SinkEventTypeTransaction:
+		event.Message = payload.Transaction
+	case payload.Message != "":
+		event.Message = payload.Message
+	case payload.Logentry.Formatted != "":
+		event.Message = payload.Logentry.Formatted
+	case payload.Logentry.Message != "":
+		event.Message = payload.Logentry.Message
+	default:
+		event.Message = exceptionMessage(payload.Exception)
+	}
+	if event.Level == "" && event.Type == SinkEventTypeEvent {
+		event.Level = string(sentry.LevelError)
+	}
+	return event, nil
+}
+
+// decodeTags reads tags sent as object or as list of key value pairs.
+func decodeTags(raw json.RawMessage) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var tags map[string]string
+	if err := json.Unmarshal(raw, &tags); err == nil {
+		return tags
+	}
+	var pairs [][2]string
+	if err := json.Unmarshal(raw, &pairs); err != nil {
+		return nil
+	}
+	tags = make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		tags[pair[0]] = pair[1]
+	}
+	return tags
+}
+
+// exceptionMessage returns type and value of the last exception, which is the outermost
+// error. Exceptions are sent as list or as object with values.
+func exceptionMessage(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var exceptions []sinkException
+	if err := json.Unmarshal(raw, &exceptions); err != nil {
+		var values struct {
+			Values []sinkException `json:"values"`
+		}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return ""
+		}
+		exceptions = values.Values
+	}
+	if len(exceptions) == 0 {
+		return ""
+	}
+	exception := exceptions[len(exceptions)-1]
+	return exception.Type + ": " + exception.Value
+}
+
+type envelopeItem struct {
+	itemType string
+	payload  []byte
+}
+
+// parseEnvelope returns the items of an envelope. Items have a JSON header line with type
+// and optional length followed by the payload.
+func parseEnvelope(ctx context.Context, body []byte) ([]envelopeItem, error) {
+	_, rest := cutLine(body)
+	var result []envelopeItem
+	for len(rest) > 0 {
+		var line []byte
+		line, rest = cutLine(rest)
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var header struct {
+			Type   string `json:"type"`
+			Length *int   `json:"length"`
+		}
+		if err := json.Unmarshal(line, &header); err != nil {
+			return nil, errors.Wrapf(ctx, err, "decode item header failed")
+		}
+		var payload []byte
+		if header.Length != nil {
+			length := *header.Length
+			if length < 0 || length > len(rest) {
+				return nil, errors.Errorf(
+					ctx,
+					"invalid item length %d with %d bytes remaining",
+					length,
+					len(rest),
+				)
+			}
+			payload, rest = rest[:length], rest[length:]
+			rest = bytes.TrimPrefix(rest, []byte("\n"))
+		} else {
+			payload, rest = cutLine(rest)
+		}
+		result = append(result, envelopeItem{itemType: header.Type, payload: payload})
+	}
+	return result, nil
+}
+
+// cutLine returns the data before the first newline and the data after it.
+func cutLine(data []byte) ([]byte, []byte) {
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	return line, rest
+}
+
+// errBodyTooLarge is returned by readBody for bodies above SinkOptions.MaxBodySize.
+var errBodyTooLarge = stderrors.New("body too large")
+
+// readBody returns the body decompressed by its Content-Encoding. Bodies of the legacy
+// store endpoint can also be base64 encoded zlib. Compressed and decompressed bodies are
+// limited to maxBodySize.
+func readBody(resp http.ResponseWriter, req *http.Request, maxBodySize int64) ([]byte, error) {
+	ctx := req.Context()
+	var reader io.Reader = http.MaxBytesReader(resp, req.Body, maxBodySize)
+	switch strings.ToLower(req.Header.Get("Content-Encoding")) {
+	case "", "identity":
+	case "gzip":
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, errors.Wrapf(ctx, err, "create gzip reader failed")
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "deflate":
+		body, err := readLimited(ctx, reader, maxBodySize)
+		if err != nil {
+			return nil, err
+		}
+		return inflate(ctx, body, maxBodySize)
+	default:
+		return nil, errors.Errorf(
+			ctx,
+			"unsupported content encoding %s",
+			req.Header.Get("Content-Encoding"),
+		)
+	}
+	body, err := readLimited(ctx, reader, maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		if decoded, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil {
+			return inflate(ctx, decoded, maxBodySize)
+		}
+	}
+	return body, nil
+}
+
+// inflate decompresses zlib or raw deflate data up to maxBodySize.
+func inflate(ctx context.Context, data []byte, maxBodySize int64) ([]byte, error) {
+	zlibReader, err := zlib.NewReader(bytes.NewReader(data))
+	if err == nil {
+		defer zlibReader.Close()
+		return readLimited(ctx, zlibReader, maxBodySize)
+	}
+	return readLimited(ctx, flate.NewReader(bytes.NewReader(data)), maxBodySize)
+}
+
+// readLimited reads the given reader and returns errBodyTooLarge if it contains more than
+// maxBodySize bytes.
+func readLimited(ctx context.Context, reader io.Reader, maxBodySize int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(reader, maxBodySize+1))
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) || int64(len(body)) > maxBodySize {
+		return nil, errors.Wrapf(ctx, errBodyTooLarge, "limit is %d bytes", maxBodySize)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(ctx, err, "read body failed")
+	}
+	return body, nil
+}
+
+// writeBodyError responds 413 for too large bodies and 400 otherwise.
+func writeBodyError(resp http.ResponseWriter, err error) {
+	if errors.Is(err, errBodyTooLarge) {
+		http.Error(resp, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(resp, err.Error(), http.StatusBadRequest)
+}
+
+func writeJSON(resp http.ResponseWriter, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	_, _ = resp.Write(body)
+}